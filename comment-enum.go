@@ -0,0 +1,76 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// commentEnumItemPattern matches the start of a documented code in a column comment,
+// e.g. the "1-" of "0-待审核 1-通过", the "1:" of "0:x,1:y" or the "1=" of "0=x;1=y".
+var commentEnumItemPattern = regexp.MustCompile(`(^|[\s,，;；、:：(（])(-?\d+)\s*[-:=：]\s*`)
+
+// commentEnumTrimChars are stripped around a description
+const commentEnumTrimChars = " \t\r\n,，;；、)）"
+
+// commentEnumStopChars end the last description, the commentary after them is no code, e.g. "2-高级, 默认 1"
+const commentEnumStopChars = ",，;；。(（"
+
+// parseCommentEnum returns the codes documented in a column comment,
+// nothing is returned unless at least two distinct codes with descriptions are found.
+func parseCommentEnum(comment string) []javaEnumConstant {
+	matches := commentEnumItemPattern.FindAllStringSubmatchIndex(comment, -1)
+	if len(matches) < 2 {
+		return nil
+	}
+	constants := make([]javaEnumConstant, 0, len(matches))
+	seen := make(map[int64]bool)
+	spaced := false
+	for i, m := range matches {
+		end := len(comment)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		code, err := strconv.ParseInt(comment[m[4]:m[5]], 10, 64)
+		if err != nil || seen[code] {
+			return nil
+		}
+		seen[code] = true
+		desc := strings.Trim(comment[m[1]:end], commentEnumTrimChars)
+		if i+1 < len(matches) {
+			spaced = spaced || strings.IndexFunc(desc, unicode.IsSpace) >= 0
+		} else {
+			desc = trimCommentEnumCommentary(desc, spaced)
+		}
+		if desc == "" {
+			return nil
+		}
+		constants = append(constants, javaEnumConstant{Code: strconv.FormatInt(code, 10), Desc: desc})
+	}
+	return constants
+}
+
+// trimCommentEnumCommentary cuts the last description of a comment at the punctuation ending the list, or at a
+// space unless the other descriptions hold spaces themselves.
+func trimCommentEnumCommentary(desc string, spaced bool) string {
+	if i := strings.IndexAny(desc, commentEnumStopChars); i >= 0 {
+		desc = desc[:i]
+	}
+	if i := strings.IndexFunc(desc, unicode.IsSpace); i >= 0 && !spaced {
+		desc = desc[:i]
+	}
+	return strings.Trim(desc, commentEnumTrimChars)
+}
+
+// commentEnumConstantName names a documented code by an ASCII description, otherwise by the code itself,
+// e.g. CODE_0 or CODE_NEGATIVE_1.
+func commentEnumConstantName(c javaEnumConstant) string {
+	fallback := "CODE_" + strings.ReplaceAll(c.Code, "-", "NEGATIVE_")
+	for _, r := range c.Desc {
+		if r > unicode.MaxASCII {
+			return fallback
+		}
+	}
+	return javaEnumConstantName(c.Desc, fallback)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCommentEnum(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    []string // code=desc
+	}{
+		{"状态: 0-待审核 1-通过 2-拒绝", []string{"0=待审核", "1=通过", "2=拒绝"}},
+		{"0:x,1:y", []string{"0=x", "1=y"}},
+		{"0=x;1=y", []string{"0=x", "1=y"}},
+		{"状态(0:禁用，1:启用)", []string{"0=禁用", "1=启用"}},
+		{"-1-删除 0-正常", []string{"-1=删除", "0=正常"}},
+		{"等级 1-初级 2-中级 3-高级, 默认 1", []string{"1=初级", "2=中级", "3=高级"}},
+		{"等级 1-初级 2-中级 3-高级 默认为初级", []string{"1=初级", "2=中级", "3=高级"}},
+		{"0:x,1:y; see the wiki", []string{"0=x", "1=y"}},
+		{"0-not paid 1-paid in full", []string{"0=not paid", "1=paid in full"}},
+		{"1-初级", nil},
+		{"0-a 0-b", nil},
+		{"用户 id", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range parseCommentEnum(tt.comment) {
+			got = append(got, c.Code+"="+c.Desc)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseCommentEnum(%q) = %v, want %v", tt.comment, got, tt.want)
+		}
+	}
+}
+
+func TestCommentEnumConstantName(t *testing.T) {
+	tests := []struct {
+		constant javaEnumConstant
+		want     string
+	}{
+		{javaEnumConstant{Code: "0", Desc: "待审核"}, "CODE_0"},
+		{javaEnumConstant{Code: "-1", Desc: "删除"}, "CODE_NEGATIVE_1"},
+		{javaEnumConstant{Code: "1", Desc: "paid in full"}, "PAID_IN_FULL"},
+	}
+	for _, tt := range tests {
+		if got := commentEnumConstantName(tt.constant); got != tt.want {
+			t.Errorf("commentEnumConstantName(%v) = %s, want %s", tt.constant, got, tt.want)
+		}
+	}
+}
+
+func TestCommentEnumFactoryConversion(t *testing.T) {
+	defer func(t, d, l string) { target, dialect, lang = t, d, l }(target, dialect, lang)
+	target, dialect = targetMybatisPlus, dialectMySQL
+	table := parseTable(t, "CREATE TABLE tb_user (id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY, status tinyint NOT NULL COMMENT '状态: 0-待审核 1-通过')")
+	tests := []struct {
+		lang string
+		file string
+		want []string
+	}{
+		{langJava, "user/infrastructure/factory/UserFactory.java", []string{
+			"import com.mahuafm.phoenix.user.domain.user.enums.UserStatus;",
+			"public static UserStatus toUserStatus(Integer code) {",
+			"var value = UserStatus.of(code);\n    if (value == null) {\n      log.warn(\"unknown UserStatus code {}\", code);",
+		}},
+		{langKotlin, "user/infrastructure/factory/UserFactory.kt", []string{
+			"import com.mahuafm.phoenix.user.domain.user.enums.UserStatus",
+			"fun toUserStatus(code: Int?): UserStatus? {",
+			"log.warn(\"unknown UserStatus code {}\", code)",
+		}},
+	}
+	for _, tt := range tests {
+		lang = tt.lang
+		codes, ok := renderTable(t, table)[tt.file]
+		if !ok {
+			t.Errorf("%s was not generated", tt.file)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(codes, want) {
+				t.Errorf("%s does not contain %q:\n%s", tt.file, want, codes)
+			}
+		}
+	}
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultConfigFile = "ddd-gen.yaml"
+
+// Config defines the project configuration file, the values a flag sets as well are overridden by the flag
+type Config struct {
+	Connection  ConnectionConfig       `yaml:"connection"`
+	Include     []string               `yaml:"include"`   // -t
+	Exclude     []string               `yaml:"exclude"`   // -exclude
+	All         bool                   `yaml:"all"`       // -all
+	Domain      string                 `yaml:"domain"`    // -D
+	DDL         string                 `yaml:"ddl"`       // -ddl
+	Snapshot    string                 `yaml:"snapshot"`  // -from-snapshot
+	FKPattern   string                 `yaml:"fkPattern"` // -fk-pattern
+	Templates   string                 `yaml:"templates"` // -templates
+	Naming      NamingConfig           `yaml:"naming"`
+	TypeMapping TypeMapping            `yaml:"typeMapping"`   // replaced by -type-map
+	Layout      ProjectLayout          `yaml:"layout"`        // replaced by -layout
+	Layers      []string               `yaml:"layers"`        // -layers
+	Skip        []string               `yaml:"skip"`          // -skip
+	Conflict    string                 `yaml:"conflict"`      // -conflict
+	Gap         bool                   `yaml:"generationGap"` // -generation-gap
+	Out         string                 `yaml:"out"`           // -out
+	Project     bool                   `yaml:"project"`       // -project
+	Target      string                 `yaml:"target"`        // -target
+	Lang        string                 `yaml:"lang"`          // -lang
+	Validation  string                 `yaml:"validation"`    // -validation
+	Tables      map[string]TableConfig `yaml:"tables"`
+
+	filename string
+	root     *yaml.Node
+}
+
+// ConnectionConfig defines the database to introspect
+type ConnectionConfig struct {
+	Dialect  string `yaml:"dialect"`  // -dialect
+	Host     string `yaml:"host"`     // -h
+	Port     int    `yaml:"port"`     // -P
+	User     string `yaml:"user"`     // -u
+	Password string `yaml:"password"` // -p
+	Database string `yaml:"database"` // -d
+	Schema   string `yaml:"schema"`   // -schema
+}
+
+// NamingConfig defines how tables are named in Java
+type NamingConfig struct {
+	TablePrefixes []string `yaml:"tablePrefixes"` // stripped from table names, the first match wins
+}
+
+// TableConfig defines the settings of a single table
+type TableConfig struct {
+	ClassName      string            `yaml:"className"`      // entity class name, the other classes are named after it
+	Domain         string            `yaml:"domain"`         // domain of the table instead of -D
+	ExcludeColumns []string          `yaml:"excludeColumns"` // columns left out of every class
+	Fields         map[string]string `yaml:"fields"`         // column -> Java field name
+	Enums          map[string]string `yaml:"enums"`          // column -> codes written like a comment, empty for no enum
+}
+
+// config holds the project configuration, the defaults unless a configuration file is found
+var config = defaultConfig()
+
+// defaultConfig returns the configuration used without a configuration file.
+func defaultConfig() *Config {
+	return &Config{
+		Naming: NamingConfig{TablePrefixes: tablePrefixes},
+		Layout: *defaultProjectLayout(),
+	}
+}
+
+// yamlErrorPattern matches the line a YAML error message starts with
+var yamlErrorPattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// readConfig loads a configuration file over the defaults, every problem found is reported with its line.
+func readConfig(filename string) (*Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := defaultConfig()
+	c.filename = filename
+	c.root = &yaml.Node{}
+	if err = yaml.Unmarshal(content, c.root); err != nil {
+		return nil, c.yamlError(err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, c.yamlError(err)
+	}
+	if err = c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// yamlError prefixes the messages of a YAML error by the file and line they refer to.
+func (c *Config) yamlError(err error) error {
+	messages := []string{err.Error()}
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		messages = typeError.Errors
+	}
+	errs := make([]error, 0, len(messages))
+	for _, message := range messages {
+		if m := yamlErrorPattern.FindStringSubmatch(strings.TrimSpace(message)); m != nil {
+			errs = append(errs, fmt.Errorf("%s:%s: %s", c.filename, m[1], m[2]))
+		} else {
+			errs = append(errs, fmt.Errorf("%s: %s", c.filename, message))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configError locates a problem of the configuration file
+type configError struct {
+	filename string
+	line     int
+	key      string
+	message  string
+}
+
+func (e *configError) Error() string {
+	return fmt.Sprintf("%s:%d: %s: %s", e.filename, e.line, e.key, e.message)
+}
+
+// errorf returns an error located at the line of a key, e.g. errorf(keys("tables", "tb_user", "domain"), ...).
+func (c *Config) errorf(path []string, format string, args ...any) error {
+	return &configError{filename: c.filename, line: c.line(path...), key: strings.Join(path, "."), message: fmt.Sprintf(format, args...)}
+}
+
+// joinConfigErrors joins errors in the order of their lines.
+func joinConfigErrors(errs []error) error {
+	line := func(err error) int {
+		var e *configError
+		if errors.As(err, &e) {
+			return e.line
+		}
+		return 0
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return line(errs[i]) < line(errs[j]) })
+	return errors.Join(errs...)
+}
+
+// keys returns a path of keys, indexes are formatted as numbers.
+func keys(path ...any) []string {
+	s := make([]string, 0, len(path))
+	for _, key := range path {
+		s = append(s, fmt.Sprint(key))
+	}
+	return s
+}
+
+// line returns the line of the deepest node found along a path of mapping keys and sequence indexes.
+func (c *Config) line(path ...string) int {
+	if c.root == nil || len(c.root.Content) == 0 {
+		return 0
+	}
+	node := c.root.Content[0]
+	line := node.Line
+	for _, key := range path {
+		switch node.Kind {
+		case yaml.MappingNode:
+			found := false
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line, node, found = node.Content[i].Line, node.Content[i+1], true
+					break
+				}
+			}
+			if !found {
+				return line
+			}
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return line
+			}
+			node = node.Content[i]
+			line = node.Line
+		default:
+			return line
+		}
+	}
+	return line
+}
+
+// validate checks the values of the configuration, table names and columns are checked once generating.
+func (c *Config) validate() error {
+	errs := make([]error, 0)
+	switch c.Connection.Dialect {
+	case "", dialectMySQL, dialectPostgres, dialectSqlite:
+	default:
+		errs = append(errs, c.errorf(keys("connection", "dialect"), "unknown dialect %q, expected mysql, postgres or sqlite", c.Connection.Dialect))
+	}
+	if c.Connection.Port < 0 || c.Connection.Port > 65535 {
+		errs = append(errs, c.errorf(keys("connection", "port"), "invalid port %d", c.Connection.Port))
+	}
+	patterns := map[string][]string{"include": c.Include, "exclude": c.Exclude}
+	for _, key := range []string{"include", "exclude"} {
+		for i, pattern := range patterns[key] {
+			if _, err := parseTablePatterns(pattern); err != nil || strings.TrimSpace(pattern) == "" {
+				errs = append(errs, c.errorf(keys(key, i), "invalid table pattern %q", pattern))
+			}
+		}
+	}
+	if c.Conflict != "" && c.Conflict != conflictNew && c.Conflict != conflictRefuse {
+		errs = append(errs, c.errorf(keys("conflict"), "unknown value %q, expected new or refuse", c.Conflict))
+	}
+	if c.Target != "" {
+		if err := validateTarget(c.Target); err != nil {
+			errs = append(errs, c.errorf(keys("target"), "unknown target %q, expected one of %s", c.Target, strings.Join(targets, ", ")))
+		}
+	}
+	if c.Lang != "" {
+		if err := validateLang(c.Lang); err != nil {
+			errs = append(errs, c.errorf(keys("lang"), "unknown lang %q, expected one of %s", c.Lang, strings.Join(langs, ", ")))
+		}
+	}
+	if err := validateValidation(c.Validation); err != nil {
+		errs = append(errs, c.errorf(keys("validation"), "unknown validation %q, expected %s or %s", c.Validation, validationJavax, validationJakarta))
+	}
+	if c.Domain != "" && !isJavaPackageName(c.Domain) {
+		errs = append(errs, c.errorf(keys("domain"), "invalid domain %q", c.Domain))
+	}
+	for i, prefix := range c.Naming.TablePrefixes {
+		if prefix == "" {
+			errs = append(errs, c.errorf(keys("naming", "tablePrefixes", i), "empty prefix"))
+		}
+	}
+	errs = append(errs, c.validateTypeMapping()...)
+	if err := c.Layout.validate(); err != nil {
+		key, message, _ := strings.Cut(err.Error(), ": ")
+		errs = append(errs, c.errorf(append(keys("layout"), strings.Split(key, ".")...), "%s", message))
+	}
+	for _, name := range sortedKeys(c.Tables) {
+		errs = append(errs, c.validateTable(name, c.Tables[name])...)
+	}
+	return joinConfigErrors(errs)
+}
+
+// validateTypeMapping checks every type mapping on its own to locate the invalid ones.
+func (c *Config) validateTypeMapping() []error {
+	errs := make([]error, 0)
+	check := func(key []string, m *TypeMapping) {
+		if _, err := newTypeMapper(c.Connection.Dialect, m); err != nil {
+			_, message, _ := strings.Cut(err.Error(), ": ")
+			errs = append(errs, c.errorf(append(keys("typeMapping"), key...), "%s", message))
+		}
+	}
+	for _, key := range sortedKeys(c.TypeMapping.Types) {
+		check(keys("types", key), &TypeMapping{Types: map[string]string{key: c.TypeMapping.Types[key]}})
+	}
+	for _, key := range sortedKeys(c.TypeMapping.Columns) {
+		check(keys("columns", key), &TypeMapping{Columns: map[string]string{key: c.TypeMapping.Columns[key]}})
+	}
+	for _, key := range sortedKeys(c.TypeMapping.Fields) {
+		check(keys("fields", key), &TypeMapping{Fields: map[string]string{key: c.TypeMapping.Fields[key]}})
+	}
+	return errs
+}
+
+// validateTable checks the settings of a table.
+func (c *Config) validateTable(name string, t TableConfig) []error {
+	errs := make([]error, 0)
+	key := keys("tables", name)
+	if t.ClassName != "" && !isJavaIdentifier(t.ClassName) {
+		errs = append(errs, c.errorf(append(key, "className"), "invalid class name %q", t.ClassName))
+	}
+	if t.Domain != "" && !isJavaPackageName(t.Domain) {
+		errs = append(errs, c.errorf(append(key, "domain"), "invalid domain %q", t.Domain))
+	}
+	for i, column := range t.ExcludeColumns {
+		if column == "" {
+			errs = append(errs, c.errorf(append(key, keys("excludeColumns", i)...), "empty column"))
+		}
+	}
+	for _, column := range sortedKeys(t.Fields) {
+		if !isJavaIdentifier(t.Fields[column]) {
+			errs = append(errs, c.errorf(append(key, "fields", column), "invalid field name %q", t.Fields[column]))
+		}
+	}
+	for _, column := range sortedKeys(t.Enums) {
+		if hint := t.Enums[column]; hint != "" && len(parseCommentEnum(hint)) == 0 {
+			errs = append(errs, c.errorf(append(key, "enums", column), "no codes found in %q, expected e.g. 0-待审核 1-通过", hint))
+		}
+	}
+	return errs
+}
+
+// apply sets the flags not given on the command line from the configuration.
+func (c *Config) apply() {
+	set := func(name, value string) {
+		if value != "" && !isFlagPassed(name) {
+			_ = flag.Set(name, value)
+		}
+	}
+	set("dialect", c.Connection.Dialect)
+	set("h", c.Connection.Host)
+	if c.Connection.Port != 0 {
+		set("P", strconv.Itoa(c.Connection.Port))
+	}
+	set("u", c.Connection.User)
+	set("p", c.Connection.Password)
+	set("d", c.Connection.Database)
+	set("schema", c.Connection.Schema)
+	// the tables and the source given on the command line replace those of the file as a whole
+	if !isFlagPassed("t") && !isFlagPassed("all") {
+		set("t", strings.Join(c.Include, ","))
+		if c.All {
+			set("all", "true")
+		}
+	}
+	set("exclude", strings.Join(c.Exclude, ","))
+	set("D", c.Domain)
+	if !isFlagPassed("ddl") && !isFlagPassed("from-snapshot") {
+		set("ddl", c.DDL)
+		set("from-snapshot", c.Snapshot)
+	}
+	set("fk-pattern", c.FKPattern)
+	set("templates", c.Templates)
+	set("layers", strings.Join(c.Layers, ","))
+	set("skip", strings.Join(c.Skip, ","))
+	set("conflict", c.Conflict)
+	if c.Gap {
+		set("generation-gap", "true")
+	}
+	set("out", c.Out)
+	if c.Project {
+		set("project", "true")
+	}
+	set("target", c.Target)
+	set("lang", c.Lang)
+	set("validation", c.Validation)
+
+	tablePrefixes = c.Naming.TablePrefixes
+	typeMapping = &c.TypeMapping
+	layout = &c.Layout
+	config = c
+}
+
+// validateLayers checks the layers and skip lists name registered layers.
+func (c *Config) validateLayers(registry *layerRegistry) error {
+	errs := make([]error, 0)
+	for key, names := range map[string][]string{"layers": c.Layers, "skip": c.Skip} {
+		for i, name := range names {
+			if !registry.has(name) {
+				errs = append(errs, c.errorf(keys(key, i), "unknown layer %q, expected one of %s", name, strings.Join(registry.names, ", ")))
+			}
+		}
+	}
+	return joinConfigErrors(errs)
+}
+
+// tableConfig returns the settings of a table.
+func tableConfig(tableName string) TableConfig {
+	return config.Tables[tableName]
+}
+
+// fieldNameOf returns the Java field name of a column, e.g. nick_name -> nickName unless renamed.
+func fieldNameOf(tableName, column string) string {
+	if name := tableConfig(tableName).Fields[column]; name != "" {
+		return name
+	}
+	return camelCase(column)
+}
+
+// excludeColumns drops the excluded columns of a table with the indexes and foreign keys on them.
+func excludeColumns(table *Table) {
+	excluded := make(map[string]bool)
+	for _, column := range tableConfig(table.Status.Name).ExcludeColumns {
+		excluded[strings.ToLower(column)] = true
+	}
+	if len(excluded) == 0 {
+		return
+	}
+	uses := func(columns []string) bool {
+		for _, column := range columns {
+			if excluded[strings.ToLower(column)] {
+				return true
+			}
+		}
+		return false
+	}
+	columns := make([]ColumnsStatement, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		if !excluded[strings.ToLower(col.Field)] {
+			columns = append(columns, col)
+		}
+	}
+	indexes := make([]IndexStatement, 0, len(table.Indexes))
+	for _, index := range table.Indexes {
+		if !uses(index.Columns) {
+			indexes = append(indexes, index)
+		}
+	}
+	foreignKeys := make([]ForeignKeyStatement, 0, len(table.ForeignKeys))
+	for _, fk := range table.ForeignKeys {
+		if !uses(fk.Columns) {
+			foreignKeys = append(foreignKeys, fk)
+		}
+	}
+	table.Columns, table.Indexes, table.ForeignKeys = columns, indexes, foreignKeys
+}
+
+// isJavaIdentifier reports whether s is a single Java identifier.
+func isJavaIdentifier(s string) bool {
+	return isJavaPackageName(s) && !strings.Contains(s, ".")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string // one per error, the file name left out
+	}{
+		{"connection:\n  dialect: oracle\n  port: 70000\n", []string{
+			`2: connection.dialect: unknown dialect "oracle", expected mysql, postgres or sqlite`,
+			"3: connection.port: invalid port 70000",
+		}},
+		{"include:\n  - tb_user\n  - '/(/'\ndomain: 9com\n", []string{
+			`3: include.1: invalid table pattern "/(/"`,
+			`4: domain: invalid domain "9com"`,
+		}},
+		{"tables:\n  tb_user:\n    className: 9x\n    fields:\n      user_name: user-name\n    enums:\n      status: none\n", []string{
+			`3: tables.tb_user.className: invalid class name "9x"`,
+			`5: tables.tb_user.fields.user_name: invalid field name "user-name"`,
+			`7: tables.tb_user.enums.status: no codes found in "none", expected e.g. 0-待审核 1-通过`,
+		}},
+		{"include:\n  - tb_user\n  - '/(/'\nconflict: skip\n", []string{
+			`3: include.1: invalid table pattern "/(/"`,
+			`4: conflict: unknown value "skip", expected new or refuse`,
+		}},
+		{"naming:\n  tablePrefixes: [tb_, '']\n", []string{"2: naming.tablePrefixes.1: empty prefix"}},
+		{"connection:\n  hots: localhost\n", []string{"2: field hots not found in type main.ConnectionConfig"}},
+		{"out: [a\n", []string{"1: did not find expected ',' or ']'"}},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join(t.TempDir(), defaultConfigFile)
+		if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := readConfig(filename)
+		if err == nil {
+			t.Errorf("readConfig(%q) succeeded, want %d error(s)", tt.content, len(tt.want))
+			continue
+		}
+		got := strings.Split(err.Error(), "\n")
+		if len(got) != len(tt.want) {
+			t.Errorf("readConfig(%q) error = %v, want %d error(s)", tt.content, err, len(tt.want))
+			continue
+		}
+		for i, want := range tt.want {
+			if got[i] != filename+":"+want {
+				t.Errorf("readConfig(%q) error %d = %s, want %s:%s", tt.content, i, got[i], filename, want)
+			}
+		}
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), defaultConfigFile)
+	content := "domain: com.example\ntables:\n  tb_user:\n    className: Member\n    excludeColumns: [password]\n"
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := readConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Domain != "com.example" || c.Tables["tb_user"].ClassName != "Member" || len(c.Tables["tb_user"].ExcludeColumns) != 1 {
+		t.Errorf("readConfig = %+v", c)
+	}
+	if len(c.Naming.TablePrefixes) == 0 {
+		t.Errorf("readConfig dropped the default table prefixes")
+	}
+}
+
+func TestConfigLine(t *testing.T) {
+	c := &Config{filename: defaultConfigFile, root: &yaml.Node{}}
+	if err := yaml.Unmarshal([]byte("a: 1\nb:\n  c:\n    - x\n    - y\n"), c.root); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path []string
+		want int
+	}{
+		{nil, 1},
+		{keys("a"), 1},
+		{keys("b", "c"), 3},
+		{keys("b", "c", 1), 5},
+		{keys("b", "c", 2), 3},
+		{keys("b", "missing"), 2},
+		{keys("a", "deeper"), 1},
+	}
+	for _, tt := range tests {
+		if got := c.line(tt.path...); got != tt.want {
+			t.Errorf("line(%v) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,765 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ddlTokenKind defines the kinds of token produced by the DDL lexer
+type ddlTokenKind int
+
+const (
+	ddlTokenEOF    ddlTokenKind = iota
+	ddlTokenIdent               // bare word or `quoted` identifier
+	ddlTokenString              // 'single' or "double" quoted literal
+	ddlTokenNumber              // numeric or b'0101' / x'ff' literal
+	ddlTokenSymbol              // punctuation such as ( ) , ; =
+)
+
+// ddlToken is a lexical token of a MySQL DDL script
+type ddlToken struct {
+	kind   ddlTokenKind
+	text   string
+	quoted bool
+	line   int
+}
+
+// newDDLIntrospector parses the CREATE TABLE statements in a SQL file.
+func newDDLIntrospector(file string) (*memoryIntrospector, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := parseDDL(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &memoryIntrospector{source: file, tables: tables}, nil
+}
+
+// parseDDL parses every CREATE TABLE statement in a MySQL script, other statements are skipped.
+func parseDDL(src string) ([]*Table, error) {
+	tokens, err := tokenizeDDL(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &ddlParser{tokens: tokens}
+	tables := make([]*Table, 0)
+	for p.peek().kind != ddlTokenEOF {
+		if p.acceptSymbol(";") {
+			continue
+		}
+		if !p.acceptKeyword("CREATE") {
+			p.skipStatement()
+			continue
+		}
+		p.acceptKeyword("TEMPORARY")
+		if !p.acceptKeyword("TABLE") {
+			p.skipStatement()
+			continue
+		}
+		t, err := p.parseCreateTable()
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			tables = append(tables, t)
+		}
+	}
+	return tables, nil
+}
+
+// tokenizeDDL splits a MySQL script into tokens, dropping whitespace and comments.
+func tokenizeDDL(src string) ([]ddlToken, error) {
+	tokens := make([]ddlToken, 0)
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(src[i:], "-- ")) || strings.HasPrefix(src[i:], "--\n"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '`':
+			text, n, ok := readDDLQuoted(src[i:], '`')
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated identifier", line)
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenIdent, text: text, quoted: true, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case c == '\'' || c == '"':
+			text, n, ok := readDDLQuoted(src[i:], c)
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenString, text: text, line: line})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+		case (c == 'b' || c == 'B' || c == 'x' || c == 'X') && i+1 < len(src) && src[i+1] == '\'':
+			end := strings.IndexByte(src[i+2:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated literal", line)
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenNumber, text: src[i : i+end+3], line: line})
+			i += end + 3
+		case isDDLDigit(c) || (c == '.' && i+1 < len(src) && isDDLDigit(src[i+1])):
+			j := i
+			for j < len(src) && (isDDLDigit(src[j]) || src[j] == '.' || isDDLWordChar(src[j])) {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenNumber, text: src[i:j], line: line})
+			i = j
+		case isDDLWordChar(c):
+			j := i
+			for j < len(src) && isDDLWordChar(src[j]) {
+				j++
+			}
+			tokens = append(tokens, ddlToken{kind: ddlTokenIdent, text: src[i:j], line: line})
+			i = j
+		default:
+			tokens = append(tokens, ddlToken{kind: ddlTokenSymbol, text: string(c), line: line})
+			i++
+		}
+	}
+	tokens = append(tokens, ddlToken{kind: ddlTokenEOF, line: line})
+	return tokens, nil
+}
+
+// readDDLQuoted reads a quoted literal starting at s[0], returns the unescaped text and the consumed length.
+func readDDLQuoted(s string, quote byte) (string, int, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote && i+1 < len(s) && s[i+1] == quote:
+			b.WriteByte(quote)
+			i++
+		case c == quote:
+			return b.String(), i + 1, true
+		case c == '\\' && quote != '`' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '0':
+				b.WriteByte(0)
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", len(s), false
+}
+
+func isDDLDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isDDLWordChar(c byte) bool {
+	return isASCIILower(c) || ('A' <= c && c <= 'Z') || isDDLDigit(c) || c == '_' || c == '$' || c >= 0x80
+}
+
+// ddlParser is a small recursive descent parser over DDL tokens
+type ddlParser struct {
+	tokens []ddlToken
+	pos    int
+}
+
+func (p *ddlParser) peek() ddlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.tokens[p.pos]
+	if t.kind != ddlTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isKeyword reports whether the upcoming tokens are the given unquoted keywords.
+func (p *ddlParser) isKeyword(keywords ...string) bool {
+	for i, kw := range keywords {
+		if p.pos+i >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.pos+i]
+		if t.kind != ddlTokenIdent || t.quoted || !strings.EqualFold(t.text, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptKeyword consumes the given keywords if they are upcoming.
+func (p *ddlParser) acceptKeyword(keywords ...string) bool {
+	if !p.isKeyword(keywords...) {
+		return false
+	}
+	p.pos += len(keywords)
+	return true
+}
+
+func (p *ddlParser) isSymbol(s string) bool {
+	t := p.peek()
+	return t.kind == ddlTokenSymbol && t.text == s
+}
+
+func (p *ddlParser) acceptSymbol(s string) bool {
+	if !p.isSymbol(s) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *ddlParser) expectSymbol(s string) error {
+	if !p.acceptSymbol(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+func (p *ddlParser) expectIdent() (string, error) {
+	t := p.peek()
+	if t.kind != ddlTokenIdent {
+		return "", p.errorf("expected identifier")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+func (p *ddlParser) expectString() (string, error) {
+	t := p.peek()
+	if t.kind != ddlTokenString {
+		return "", p.errorf("expected string literal")
+	}
+	p.pos++
+	return t.text, nil
+}
+
+func (p *ddlParser) errorf(format string, args ...any) error {
+	t := p.peek()
+	got := t.text
+	if t.kind == ddlTokenEOF {
+		got = "end of file"
+	}
+	return fmt.Errorf("line %d: %s, got %q", t.line, fmt.Sprintf(format, args...), got)
+}
+
+// skipStatement skips tokens up to and including the next top level ';'.
+func (p *ddlParser) skipStatement() {
+	for t := p.peek(); t.kind != ddlTokenEOF; t = p.peek() {
+		if p.isSymbol("(") {
+			p.skipParens()
+			continue
+		}
+		p.next()
+		if t.kind == ddlTokenSymbol && t.text == ";" {
+			return
+		}
+	}
+}
+
+// skipParens consumes a balanced parenthesized group and returns its tokens, parentheses included.
+func (p *ddlParser) skipParens() []ddlToken {
+	start := p.pos
+	depth := 0
+	for t := p.peek(); t.kind != ddlTokenEOF; t = p.peek() {
+		p.next()
+		if t.kind == ddlTokenSymbol && t.text == "(" {
+			depth++
+		} else if t.kind == ddlTokenSymbol && t.text == ")" {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return p.tokens[start:p.pos]
+}
+
+// skipDefinitionRest skips tokens up to the ',' or ')' that ends the current table element.
+func (p *ddlParser) skipDefinitionRest() {
+	for t := p.peek(); t.kind != ddlTokenEOF; t = p.peek() {
+		if p.isSymbol(",") || p.isSymbol(")") {
+			return
+		}
+		if p.isSymbol("(") {
+			p.skipParens()
+			continue
+		}
+		p.next()
+	}
+}
+
+// parseCreateTable parses the rest of a CREATE TABLE statement, returns nil for CREATE TABLE ... LIKE/AS.
+func (p *ddlParser) parseCreateTable() (*Table, error) {
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol(".") {
+		if name, err = p.expectIdent(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.acceptSymbol("(") {
+		p.skipStatement()
+		return nil, nil
+	}
+
+	t := &Table{
+		Status:      TableStatus{Name: name},
+		Columns:     make([]ColumnsStatement, 0),
+		Indexes:     make([]IndexStatement, 0),
+		ForeignKeys: make([]ForeignKeyStatement, 0),
+	}
+	for {
+		if err = p.parseTableElement(t); err != nil {
+			return nil, err
+		}
+		if p.acceptSymbol(",") {
+			continue
+		}
+		if err = p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		break
+	}
+	if err = p.parseTableOptions(t); err != nil {
+		return nil, err
+	}
+	completeDDLTable(t)
+	return t, nil
+}
+
+// parseTableElement parses a column, index or constraint definition.
+func (p *ddlParser) parseTableElement(t *Table) error {
+	constraint := ""
+	if p.acceptKeyword("CONSTRAINT") {
+		if !p.isKeyword("PRIMARY") && !p.isKeyword("UNIQUE") && !p.isKeyword("FOREIGN") && !p.isKeyword("CHECK") {
+			var err error
+			if constraint, err = p.expectIdent(); err != nil {
+				return err
+			}
+		}
+	}
+	switch {
+	case p.acceptKeyword("PRIMARY", "KEY"):
+		return p.parseIndex(t, IndexStatement{Name: "PRIMARY", Primary: true, Unique: true}, false)
+	case p.acceptKeyword("UNIQUE"):
+		_ = p.acceptKeyword("INDEX") || p.acceptKeyword("KEY")
+		return p.parseIndex(t, IndexStatement{Name: constraint, Unique: true}, true)
+	case p.acceptKeyword("INDEX"), p.acceptKeyword("KEY"):
+		return p.parseIndex(t, IndexStatement{}, true)
+	case p.acceptKeyword("FULLTEXT"), p.acceptKeyword("SPATIAL"):
+		_ = p.acceptKeyword("INDEX") || p.acceptKeyword("KEY")
+		return p.parseIndex(t, IndexStatement{}, true)
+	case p.acceptKeyword("FOREIGN", "KEY"):
+		return p.parseForeignKey(t, constraint)
+	case p.isKeyword("CHECK"):
+		p.skipDefinitionRest()
+		return nil
+	}
+	return p.parseColumn(t)
+}
+
+// parseIndex parses `[name] [USING type] (key_part, ...) [options]`.
+func (p *ddlParser) parseIndex(t *Table, index IndexStatement, named bool) error {
+	if named && p.peek().kind == ddlTokenIdent && !p.isKeyword("USING") {
+		index.Name = p.next().text
+	}
+	if p.acceptKeyword("USING") {
+		p.next()
+	}
+	columns, err := p.parseKeyParts()
+	if err != nil {
+		return err
+	}
+	index.Columns = columns
+	if index.Name == "" && len(columns) > 0 {
+		index.Name = columns[0]
+	}
+	p.skipDefinitionRest()
+	t.Indexes = append(t.Indexes, index)
+	return nil
+}
+
+// parseKeyParts parses `(key_part, ...)` and returns the column names, functional key parts are skipped.
+func (p *ddlParser) parseKeyParts() ([]string, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	columns := make([]string, 0)
+	for {
+		if p.isSymbol("(") {
+			p.skipParens()
+		} else {
+			column, err := p.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, column)
+			if p.isSymbol("(") {
+				p.skipParens()
+			}
+		}
+		_ = p.acceptKeyword("ASC") || p.acceptKeyword("DESC")
+		if p.acceptSymbol(",") {
+			continue
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return columns, nil
+	}
+}
+
+// parseForeignKey parses `[index_name] (col, ...) REFERENCES tbl (col, ...) [ON DELETE|UPDATE option]`.
+func (p *ddlParser) parseForeignKey(t *Table, constraint string) error {
+	fk := ForeignKeyStatement{Name: constraint}
+	if p.peek().kind == ddlTokenIdent {
+		name := p.next().text
+		if fk.Name == "" {
+			fk.Name = name
+		}
+	}
+	var err error
+	if fk.Columns, err = p.parseKeyParts(); err != nil {
+		return err
+	}
+	if !p.acceptKeyword("REFERENCES") {
+		return p.errorf("expected REFERENCES")
+	}
+	if fk.ReferencedTable, err = p.expectIdent(); err != nil {
+		return err
+	}
+	if p.acceptSymbol(".") {
+		if fk.ReferencedTable, err = p.expectIdent(); err != nil {
+			return err
+		}
+	}
+	if fk.ReferencedColumns, err = p.parseKeyParts(); err != nil {
+		return err
+	}
+	if fk.Name == "" {
+		fk.Name = fmt.Sprintf("%s_ibfk_%d", t.Status.Name, len(t.ForeignKeys)+1)
+	}
+	p.skipDefinitionRest()
+	t.ForeignKeys = append(t.ForeignKeys, fk)
+	return nil
+}
+
+// parseColumn parses `name data_type [attributes]`.
+func (p *ddlParser) parseColumn(t *Table) error {
+	name, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	dataType, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	col := ColumnsStatement{
+		Field:           name,
+		Type:            strings.ToLower(dataType),
+		Null:            "YES",
+		DataType:        strings.ToLower(dataType),
+		OrdinalPosition: len(t.Columns) + 1,
+	}
+	if p.isSymbol("(") {
+		col.Type += ddlTokensText(p.skipParens())
+	}
+
+	extras := make([]string, 0)
+	for !p.isSymbol(",") && !p.isSymbol(")") && p.peek().kind != ddlTokenEOF {
+		switch {
+		case p.acceptKeyword("UNSIGNED"):
+			col.Type += " unsigned"
+			col.Unsigned = true
+		case p.acceptKeyword("ZEROFILL"):
+			col.Type += " zerofill"
+			col.Unsigned = true
+		case p.acceptKeyword("SIGNED"):
+		case p.acceptKeyword("CHARACTER", "SET"), p.acceptKeyword("CHARSET"):
+			p.next()
+		case p.acceptKeyword("COLLATE"):
+			col.Collation = p.next().text
+		case p.acceptKeyword("NOT", "NULL"):
+			col.Null = "NO"
+		case p.acceptKeyword("NULL"):
+			col.Null = "YES"
+		case p.acceptKeyword("DEFAULT"):
+			col.HasDefault = !p.isKeyword("NULL")
+			col.Default = p.parseDefaultValue()
+		case p.acceptKeyword("ON", "UPDATE"):
+			extras = append(extras, "on update "+p.parseDefaultValue())
+		case p.acceptKeyword("AUTO_INCREMENT"):
+			extras = append(extras, "auto_increment")
+		case p.acceptKeyword("COMMENT"):
+			if col.Comment, err = p.expectString(); err != nil {
+				return err
+			}
+		case p.acceptKeyword("PRIMARY", "KEY"), p.acceptKeyword("KEY"):
+			t.Indexes = append(t.Indexes, IndexStatement{Name: "PRIMARY", Primary: true, Unique: true, Columns: []string{name}})
+		case p.acceptKeyword("UNIQUE"):
+			p.acceptKeyword("KEY")
+			t.Indexes = append(t.Indexes, IndexStatement{Name: name, Unique: true, Columns: []string{name}})
+		case p.acceptKeyword("GENERATED", "ALWAYS", "AS"), p.acceptKeyword("AS"):
+			expression := p.skipParens()
+			if len(expression) > 2 {
+				col.GenerationExpression = ddlTokensText(expression[1 : len(expression)-1])
+			}
+			generated := "VIRTUAL GENERATED"
+			if p.acceptKeyword("STORED") || p.acceptKeyword("PERSISTENT") {
+				generated = "STORED GENERATED"
+			}
+			p.acceptKeyword("VIRTUAL")
+			extras = append(extras, generated)
+		case p.isKeyword("REFERENCES"), p.isKeyword("CHECK"):
+			// inline REFERENCES is parsed but ignored by MySQL
+			p.skipDefinitionRest()
+		case p.isSymbol("("):
+			p.skipParens()
+		default:
+			p.next()
+		}
+	}
+	col.Extra = strings.Join(extras, " ")
+	t.Columns = append(t.Columns, col)
+	return nil
+}
+
+// parseDefaultValue parses a DEFAULT / ON UPDATE value, string literals are returned unquoted like SHOW COLUMNS does.
+func (p *ddlParser) parseDefaultValue() string {
+	if p.isSymbol("(") {
+		return ddlTokensText(p.skipParens())
+	}
+	if p.acceptKeyword("NULL") {
+		return ""
+	}
+	sign := ""
+	if p.acceptSymbol("-") {
+		sign = "-"
+	} else {
+		p.acceptSymbol("+")
+	}
+	t := p.next()
+	if t.kind == ddlTokenIdent && p.isSymbol("(") {
+		return t.text + ddlTokensText(p.skipParens())
+	}
+	return sign + t.text
+}
+
+// parseTableOptions parses the options after the column list up to the end of the statement.
+func (p *ddlParser) parseTableOptions(t *Table) error {
+	for !p.acceptSymbol(";") && p.peek().kind != ddlTokenEOF {
+		switch {
+		case p.acceptKeyword("COMMENT"):
+			p.acceptSymbol("=")
+			comment, err := p.expectString()
+			if err != nil {
+				return err
+			}
+			t.Status.Comment = comment
+		case p.acceptKeyword("COLLATE"):
+			p.acceptSymbol("=")
+			collation, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			t.Status.Collation = collation
+		case p.isSymbol("("):
+			p.skipParens()
+		default:
+			p.next()
+		}
+	}
+	return nil
+}
+
+// completeDDLTable fills what information_schema would derive from the definitions:
+// the implicit index of every foreign key, ColumnsStatement.Key as PRI/UNI/MUL, lengths and collations.
+func completeDDLTable(t *Table) {
+	for _, fk := range t.ForeignKeys {
+		if !hasLeadingIndex(t.Indexes, fk.Columns) {
+			t.Indexes = append(t.Indexes, IndexStatement{Name: fk.Name, Columns: fk.Columns})
+		}
+	}
+
+	keys := columnKeys(t.Indexes)
+	for i := range t.Columns {
+		col := &t.Columns[i]
+		col.Key = keys[strings.ToLower(col.Field)]
+		if col.Key == "PRI" {
+			col.Null = "NO"
+		}
+		completeColumnType(col)
+		if col.Collation == "" && col.CharMaxLength > 0 && !strings.Contains(col.DataType, "binary") && !strings.Contains(col.DataType, "blob") {
+			col.Collation = t.Status.Collation
+		}
+	}
+}
+
+// hasLeadingIndex reports whether an index starts with the given columns.
+func hasLeadingIndex(indexes []IndexStatement, columns []string) bool {
+	for _, index := range indexes {
+		if len(index.Columns) < len(columns) {
+			continue
+		}
+		matched := true
+		for i, column := range columns {
+			if !strings.EqualFold(index.Columns[i], column) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// completeColumnType fills lengths, precision and scale from the column type like information_schema.COLUMNS does.
+func completeColumnType(col *ColumnsStatement) {
+	inner := ""
+	if open, end := strings.IndexByte(col.Type, '('), strings.LastIndexByte(col.Type, ')'); open >= 0 && end > open {
+		inner = col.Type[open+1 : end]
+	}
+	args := make([]int64, 0)
+	for _, arg := range strings.Split(inner, ",") {
+		var n int64
+		if _, err := fmt.Sscanf(strings.TrimSpace(arg), "%d", &n); err == nil {
+			args = append(args, n)
+		}
+	}
+	arg := func(i int, def int64) int64 {
+		if i < len(args) {
+			return args[i]
+		}
+		return def
+	}
+
+	switch col.DataType {
+	case "char", "binary":
+		col.CharMaxLength = arg(0, 1)
+	case "varchar", "varbinary":
+		col.CharMaxLength = arg(0, 0)
+	case "tinytext", "tinyblob":
+		col.CharMaxLength = 255
+	case "text", "blob":
+		col.CharMaxLength = 65535
+	case "mediumtext", "mediumblob":
+		col.CharMaxLength = 16777215
+	case "longtext", "longblob", "json":
+		col.CharMaxLength = 4294967295
+	case "enum", "set":
+		col.CharMaxLength = 1
+		for _, value := range parseTypeLiterals(col.Type) {
+			col.CharMaxLength = max(col.CharMaxLength, int64(len([]rune(value))))
+		}
+	case "tinyint":
+		col.NumericPrecision = 3
+	case "smallint":
+		col.NumericPrecision = 5
+	case "mediumint":
+		col.NumericPrecision = 7
+	case "int", "integer":
+		col.NumericPrecision = 10
+	case "bigint":
+		col.NumericPrecision = 19
+		if col.Unsigned {
+			col.NumericPrecision = 20
+		}
+	case "bit":
+		col.NumericPrecision = arg(0, 1)
+	case "decimal", "numeric", "dec", "fixed":
+		col.NumericPrecision = arg(0, 10)
+		col.NumericScale = arg(1, 0)
+	case "float", "double", "real":
+		col.NumericPrecision = arg(0, 12)
+		if col.DataType != "float" {
+			col.NumericPrecision = arg(0, 22)
+		}
+		col.NumericScale = arg(1, 0)
+	}
+}
+
+// parseTypeLiterals returns the unescaped quoted values of a column type, e.g. enum('a','b') -> a, b.
+func parseTypeLiterals(columnType string) []string {
+	open := strings.IndexByte(columnType, '(')
+	if open < 0 {
+		return nil
+	}
+	literals := make([]string, 0)
+	var b strings.Builder
+	quoted := false
+	for i := open + 1; i < len(columnType); i++ {
+		c := columnType[i]
+		switch {
+		case !quoted && c == '\'':
+			quoted = true
+			b.Reset()
+		case !quoted && c == ')':
+			return literals
+		case quoted && c == '\\' && i+1 < len(columnType):
+			i++
+			b.WriteByte(columnType[i])
+		case quoted && c == '\'' && i+1 < len(columnType) && columnType[i+1] == '\'':
+			i++
+			b.WriteByte(c)
+		case quoted && c == '\'':
+			quoted = false
+			literals = append(literals, b.String())
+		case quoted:
+			b.WriteByte(c)
+		}
+	}
+	return literals
+}
+
+// ddlTokensText renders tokens back to SQL text, e.g. enum('a','b') or (10,2).
+func ddlTokensText(tokens []ddlToken) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && t.kind != ddlTokenSymbol && tokens[i-1].kind != ddlTokenSymbol {
+			b.WriteByte(' ')
+		}
+		switch t.kind {
+		case ddlTokenString:
+			b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+		case ddlTokenIdent:
+			if t.quoted {
+				b.WriteString("`" + t.text + "`")
+			} else {
+				b.WriteString(t.text)
+			}
+		default:
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
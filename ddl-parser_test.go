@@ -0,0 +1,124 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeDDL(t *testing.T) {
+	tokens, err := tokenizeDDL("-- a comment\n# another\n/* block\n */ CREATE `a``b` 'it''s\\n' b'01' 10.5 (x);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ddlToken{
+		{kind: ddlTokenIdent, text: "CREATE", line: 4},
+		{kind: ddlTokenIdent, text: "a`b", quoted: true, line: 4},
+		{kind: ddlTokenString, text: "it's\n", line: 4},
+		{kind: ddlTokenNumber, text: "b'01'", line: 4},
+		{kind: ddlTokenNumber, text: "10.5", line: 4},
+		{kind: ddlTokenSymbol, text: "(", line: 4},
+		{kind: ddlTokenIdent, text: "x", line: 4},
+		{kind: ddlTokenSymbol, text: ")", line: 4},
+		{kind: ddlTokenSymbol, text: ";", line: 4},
+		{kind: ddlTokenEOF, line: 4},
+	}
+	if !slices.Equal(tokens, want) {
+		t.Errorf("tokenizeDDL = %+v, want %+v", tokens, want)
+	}
+
+	for _, src := range []string{"/* open", "`open", "'open", "x'ff"} {
+		if _, err = tokenizeDDL("\n" + src); err == nil || !strings.HasPrefix(err.Error(), "line 2: unterminated") {
+			t.Errorf("tokenizeDDL(%q) error = %v, want an unterminated error on line 2", src, err)
+		}
+	}
+}
+
+func TestParseDDL(t *testing.T) {
+	tables, err := parseDDL(`
+DROP TABLE IF EXISTS tb_order;
+INSERT INTO tb_user VALUES (1, 'a;b');
+CREATE TABLE IF NOT EXISTS db.tb_order (
+  id bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '主键',
+  code varchar(32) CHARACTER SET utf8mb4 NOT NULL DEFAULT '',
+  price decimal(10,2) DEFAULT NULL,
+  status enum('new','paid') NOT NULL DEFAULT 'new',
+  user_id bigint NOT NULL,
+  total int AS (price * 2) STORED,
+  mtime datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_code (code(10)),
+  KEY idx_status_user (status, user_id DESC),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES tb_user (id) ON DELETE CASCADE,
+  CHECK (price > 0)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin COMMENT='订单';
+CREATE TABLE tb_copy LIKE tb_order;
+CREATE TABLE t (a int PRIMARY KEY, b int UNIQUE);
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("parseDDL returned %d tables, want 2", len(tables))
+	}
+
+	order := tables[0]
+	if order.Status != (TableStatus{Name: "tb_order", Collation: "utf8mb4_bin", Comment: "订单"}) {
+		t.Errorf("status = %+v", order.Status)
+	}
+	columns := []ColumnsStatement{
+		{Field: "id", Type: "bigint(20) unsigned", Null: "NO", Key: "PRI", Comment: "主键", Extra: "auto_increment", DataType: "bigint", NumericPrecision: 20, Unsigned: true, OrdinalPosition: 1},
+		{Field: "code", Type: "varchar(32)", Null: "NO", Key: "UNI", HasDefault: true, DataType: "varchar", CharMaxLength: 32, OrdinalPosition: 2, Collation: "utf8mb4_bin"},
+		{Field: "price", Type: "decimal(10,2)", Null: "YES", DataType: "decimal", NumericPrecision: 10, NumericScale: 2, OrdinalPosition: 3},
+		{Field: "status", Type: "enum('new','paid')", Null: "NO", Key: "MUL", Default: "new", HasDefault: true, DataType: "enum", CharMaxLength: 4, OrdinalPosition: 4, Collation: "utf8mb4_bin"},
+		{Field: "user_id", Type: "bigint", Null: "NO", Key: "MUL", DataType: "bigint", NumericPrecision: 19, OrdinalPosition: 5},
+		{Field: "total", Type: "int", Null: "YES", Extra: "STORED GENERATED", DataType: "int", NumericPrecision: 10, GenerationExpression: "price*2", OrdinalPosition: 6},
+		{Field: "mtime", Type: "datetime", Null: "NO", Default: "CURRENT_TIMESTAMP", HasDefault: true, Extra: "on update CURRENT_TIMESTAMP", DataType: "datetime", OrdinalPosition: 7},
+	}
+	if len(order.Columns) != len(columns) {
+		t.Fatalf("got %d columns, want %d", len(order.Columns), len(columns))
+	}
+	for i, want := range columns {
+		if order.Columns[i] != want {
+			t.Errorf("column %d = %+v\nwant %+v", i, order.Columns[i], want)
+		}
+	}
+
+	indexes := []IndexStatement{
+		{Name: "PRIMARY", Primary: true, Unique: true, Columns: []string{"id"}},
+		{Name: "uk_code", Unique: true, Columns: []string{"code"}},
+		{Name: "idx_status_user", Columns: []string{"status", "user_id"}},
+		{Name: "fk_user", Columns: []string{"user_id"}},
+	}
+	if !slices.EqualFunc(order.Indexes, indexes, equalIndex) {
+		t.Errorf("indexes = %+v, want %+v", order.Indexes, indexes)
+	}
+	if len(order.ForeignKeys) != 1 || order.ForeignKeys[0].Name != "fk_user" || order.ForeignKeys[0].ReferencedTable != "tb_user" ||
+		!slices.Equal(order.ForeignKeys[0].Columns, []string{"user_id"}) || !slices.Equal(order.ForeignKeys[0].ReferencedColumns, []string{"id"}) {
+		t.Errorf("foreign keys = %+v", order.ForeignKeys)
+	}
+
+	inline := tables[1]
+	if inline.Columns[0].Key != "PRI" || inline.Columns[0].Null != "NO" || inline.Columns[1].Key != "UNI" {
+		t.Errorf("inline keys = %+v", inline.Columns)
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"CREATE TABLE t (a int", `line 1: expected ")", got "end of file"`},
+		{"CREATE TABLE t (\n  a int,\n  FOREIGN KEY (a) tb_b (id)\n)", "line 3: expected REFERENCES"},
+		{"CREATE TABLE t (a int) COMMENT = x", `line 1: expected string literal, got "x"`},
+	}
+	for _, tt := range tests {
+		if _, err := parseDDL(tt.src); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("parseDDL(%q) error = %v, want %s", tt.src, err, tt.want)
+		}
+	}
+}
+
+func equalIndex(a, b IndexStatement) bool {
+	return a.Name == b.Name && a.Primary == b.Primary && a.Unique == b.Unique && slices.Equal(a.Columns, b.Columns)
+}
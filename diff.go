@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes of a hunk
+const diffContext = 3
+
+// diffOp is a line kept, deleted from the old text or inserted by the new one
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	a, b int  // line indexes in the old and the new text
+	line string
+}
+
+// splitLines splits a text into lines, without the empty one a trailing newline would add.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script turning a into b, by the Myers algorithm.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	trace := make([][]int, 0)
+	for d := 0; d <= offset; d++ {
+		trace = append(trace, append([]int{}, v...))
+		done := false
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+	}
+
+	// walk back from the end through the saved frontiers
+	ops := make([]diffOp, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, diffOp{kind: ' ', a: x, b: y, line: a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{kind: '+', a: x, b: y, line: b[y]})
+		} else {
+			x--
+			ops = append(ops, diffOp{kind: '-', a: x, b: y, line: a[x]})
+		}
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff returns the unified diff of two texts, nothing if they are equal.
+// An empty oldName marks a file being created.
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+	changed := make([]int, 0)
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	if oldName == "" {
+		b.WriteString("--- /dev/null\n")
+	} else {
+		b.WriteString(fmt.Sprintf("--- a/%s\n", oldName))
+	}
+	b.WriteString(fmt.Sprintf("+++ b/%s\n", newName))
+	for i := 0; i < len(changed); {
+		// a hunk takes every change separated from the previous one by at most twice the context
+		start := max(changed[i]-diffContext, 0)
+		j := i
+		for j+1 < len(changed) && changed[j+1]-changed[j] <= 2*diffContext+1 {
+			j++
+		}
+		end := min(changed[j]+diffContext+1, len(ops))
+		hunk := ops[start:end]
+
+		aStart, bStart, aLen, bLen := hunk[0].a, hunk[0].b, 0, 0
+		for _, op := range hunk {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		b.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen)))
+		for _, op := range hunk {
+			b.WriteString(string(op.kind) + op.line + "\n")
+		}
+		i = j + 1
+	}
+	return b.String()
+}
+
+// hunkRange formats the line range of a hunk, an empty range names the line before it.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
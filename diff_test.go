@@ -0,0 +1,96 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int // lines deleted or inserted by the shortest edit script
+	}{
+		{"", "", 0},
+		{"a\nb\n", "a\nb\n", 0},
+		{"", "a\nb\n", 2},
+		{"a\nb\n", "", 2},
+		{"a\nb\nc\n", "a\nx\nc\n", 2},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n", 5},
+		{"x\na\nb\n", "a\nb\ny\n", 2},
+	}
+	for _, tt := range tests {
+		a, b := splitLines(tt.a), splitLines(tt.b)
+		ops := diffLines(a, b)
+		var oldLines, newLines []string
+		edits := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				oldLines = append(oldLines, op.line)
+			}
+			if op.kind != '-' {
+				newLines = append(newLines, op.line)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if !slices.Equal(oldLines, a) || !slices.Equal(newLines, b) {
+			t.Errorf("diffLines(%q, %q) = %+v does not turn one into the other", tt.a, tt.b, ops)
+		}
+		if edits != tt.edits {
+			t.Errorf("diffLines(%q, %q) makes %d edits, want %d", tt.a, tt.b, edits, tt.edits)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name             string
+		oldName, oldText string
+		newText, want    string
+	}{
+		{"equal", "A.java", "a\nb\n", "a\nb\n", ""},
+		{"created", "", "", "a\nb\n", "--- /dev/null\n+++ b/A.java\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"deleted", "A.java", "a\n", "", "--- a/A.java\n+++ b/A.java\n@@ -1 +0,0 @@\n-a\n"},
+		{
+			"one hunk", "A.java",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n",
+			"--- a/A.java\n+++ b/A.java\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"two hunks", "A.java",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			"--- a/A.java\n+++ b/A.java\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			"merged hunks", "A.java",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"one\n2\n3\n4\n5\n6\n7\neight\n",
+			"--- a/A.java\n+++ b/A.java\n@@ -1,8 +1,8 @@\n-1\n+one\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := unifiedDiff(tt.oldName, "A.java", tt.oldText, tt.newText); got != tt.want {
+			t.Errorf("%s: unifiedDiff =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		start, length int
+		want          string
+	}{
+		{0, 0, "0,0"},
+		{4, 0, "4,0"},
+		{0, 1, "1"},
+		{2, 5, "3,5"},
+	}
+	for _, tt := range tests {
+		if got := hunkRange(tt.start, tt.length); got != tt.want {
+			t.Errorf("hunkRange(%d, %d) = %s, want %s", tt.start, tt.length, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	sqlListTables = "SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME"
+
+	sqlSelectTable = "SELECT TABLE_NAME AS `name`, IFNULL(TABLE_ROWS, 0) AS `rows`, IFNULL(TABLE_COLLATION, '') AS `collation`, " +
+		"TABLE_COMMENT AS `comment` FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?"
+
+	sqlSelectColumns = "SELECT COLUMN_NAME AS `field`, COLUMN_TYPE AS `type`, IS_NULLABLE AS `null`, COLUMN_KEY AS `key`, " +
+		"COLUMN_COMMENT AS `comment`, IFNULL(COLUMN_DEFAULT, '') AS `default`, COLUMN_DEFAULT IS NOT NULL AS `has_default`, " +
+		"EXTRA AS `extra`, DATA_TYPE AS `data_type`, " +
+		"IFNULL(CHARACTER_MAXIMUM_LENGTH, 0) AS `char_max_length`, IFNULL(NUMERIC_PRECISION, 0) AS `numeric_precision`, " +
+		"IFNULL(NUMERIC_SCALE, 0) AS `numeric_scale`, COLUMN_TYPE LIKE '%unsigned%' AS `unsigned`, " +
+		"IFNULL(GENERATION_EXPRESSION, '') AS `generation_expression`, ORDINAL_POSITION AS `ordinal_position`, " +
+		"IFNULL(COLLATION_NAME, '') AS `collation` " +
+		"FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION"
+
+	sqlSelectIndexes = "SELECT INDEX_NAME AS `name`, INDEX_NAME = 'PRIMARY' AS `primary`, NON_UNIQUE AS `non_unique`, " +
+		"IFNULL(COLUMN_NAME, '') AS `column_name` " +
+		"FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? " +
+		"ORDER BY INDEX_NAME <> 'PRIMARY', INDEX_NAME, SEQ_IN_INDEX"
+
+	sqlSelectForeignKeys = "SELECT CONSTRAINT_NAME AS `name`, COLUMN_NAME AS `column_name`, " +
+		"REFERENCED_TABLE_NAME AS `referenced_table`, REFERENCED_COLUMN_NAME AS `referenced_column` " +
+		"FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL " +
+		"ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION"
+)
+
+// mysqlIntrospector reads tables from MySQL information_schema
+type mysqlIntrospector struct {
+	db     *gorm.DB
+	schema string
+}
+
+// newMySQLIntrospector connects to the MySQL database selected by flags.
+func newMySQLIntrospector() (*mysqlIntrospector, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", username, password, host, port, schemaName)
+	fmt.Printf("dsn: %s\n\n", dsn)
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlIntrospector{db: db, schema: schemaName}, nil
+}
+
+func (m *mysqlIntrospector) ListTables() ([]string, error) {
+	names := make([]string, 0)
+	if err := m.db.Raw(sqlListTables, m.schema).Scan(&names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (m *mysqlIntrospector) DescribeTable(name string) (*Table, error) {
+	table := &Table{}
+	result := m.db.Raw(sqlSelectTable, m.schema, name).Scan(&table.Status)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("table %s.%s not found", m.schema, name)
+	}
+	table.Columns = make([]ColumnsStatement, 0)
+	if err := m.db.Raw(sqlSelectColumns, m.schema, name).Scan(&table.Columns).Error; err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (m *mysqlIntrospector) DescribeIndexes(name string) ([]IndexStatement, error) {
+	rows := make([]indexColumnRow, 0)
+	if err := m.db.Raw(sqlSelectIndexes, m.schema, name).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return groupIndexRows(rows), nil
+}
+
+func (m *mysqlIntrospector) DescribeForeignKeys(name string) ([]ForeignKeyStatement, error) {
+	rows := make([]foreignKeyColumnRow, 0)
+	if err := m.db.Raw(sqlSelectForeignKeys, m.schema, name).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return groupForeignKeyRows(rows), nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"strings"
+)
+
+const (
+	sqlPgListTables = "SELECT table_name FROM information_schema.tables " +
+		"WHERE table_schema = ? AND table_type = 'BASE TABLE' ORDER BY table_name"
+
+	sqlPgSelectTable = `SELECT c.relname AS "name", GREATEST(c.reltuples, 0)::bigint AS "rows", '' AS "collation",
+       COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), '') AS "comment"
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = ? AND c.relname = ? AND c.relkind IN ('r', 'p')`
+
+	sqlPgSelectColumns = `SELECT col.column_name AS "field",
+       pg_catalog.format_type(a.atttypid, a.atttypmod) AS "type",
+       col.is_nullable AS "null",
+       COALESCE(pg_catalog.col_description(a.attrelid, a.attnum), '') AS "comment",
+       CASE WHEN col.is_generated = 'ALWAYS' THEN '' ELSE COALESCE(col.column_default, '') END AS "default",
+       col.is_generated <> 'ALWAYS' AND col.column_default IS NOT NULL AS "has_default",
+       CASE
+           WHEN col.is_identity = 'YES' OR col.column_default LIKE 'nextval(%' THEN 'auto_increment'
+           WHEN col.is_generated = 'ALWAYS' THEN 'STORED GENERATED'
+           ELSE ''
+       END AS "extra",
+       col.udt_name AS "data_type",
+       COALESCE(col.character_maximum_length, 0) AS "char_max_length",
+       COALESCE(col.numeric_precision, 0) AS "numeric_precision",
+       COALESCE(col.numeric_scale, 0) AS "numeric_scale",
+       false AS "unsigned",
+       COALESCE(col.generation_expression, '') AS "generation_expression",
+       col.ordinal_position AS "ordinal_position",
+       COALESCE(col.collation_name, '') AS "collation"
+FROM information_schema.columns col
+JOIN pg_catalog.pg_namespace n ON n.nspname = col.table_schema
+JOIN pg_catalog.pg_class c ON c.relnamespace = n.oid AND c.relname = col.table_name
+JOIN pg_catalog.pg_attribute a ON a.attrelid = c.oid AND a.attname = col.column_name
+WHERE col.table_schema = ? AND col.table_name = ?
+ORDER BY col.ordinal_position`
+
+	sqlPgSelectIndexes = `SELECT i.relname AS "name", ix.indisprimary AS "primary", NOT ix.indisunique AS "non_unique",
+       COALESCE(a.attname, '') AS "column_name"
+FROM pg_catalog.pg_index ix
+JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, seq)
+LEFT JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE n.nspname = ? AND t.relname = ? AND k.seq <= ix.indnkeyatts
+ORDER BY ix.indisprimary DESC, i.relname, k.seq`
+
+	sqlPgSelectForeignKeys = `SELECT con.conname AS "name", a.attname AS "column_name",
+       rt.relname AS "referenced_table", ra.attname AS "referenced_column"
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class t ON t.oid = con.conrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+JOIN pg_catalog.pg_class rt ON rt.oid = con.confrelid
+CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, seq)
+JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+JOIN pg_catalog.pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+WHERE con.contype = 'f' AND n.nspname = ? AND t.relname = ?
+ORDER BY con.conname, k.seq`
+)
+
+// postgresIntrospector reads tables from PostgreSQL pg_catalog and information_schema
+type postgresIntrospector struct {
+	db     *gorm.DB
+	schema string
+}
+
+// newPostgresIntrospector connects to the PostgreSQL database selected by flags.
+func newPostgresIntrospector() (*postgresIntrospector, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=prefer", host, port, username, password, schemaName)
+	fmt.Printf("dsn: %s\n\n", dsn)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &postgresIntrospector{db: db, schema: pgSchemaName}, nil
+}
+
+func (p *postgresIntrospector) ListTables() ([]string, error) {
+	names := make([]string, 0)
+	if err := p.db.Raw(sqlPgListTables, p.schema).Scan(&names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (p *postgresIntrospector) DescribeTable(name string) (*Table, error) {
+	table := &Table{}
+	result := p.db.Raw(sqlPgSelectTable, p.schema, name).Scan(&table.Status)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("table %s.%s not found", p.schema, name)
+	}
+	table.Columns = make([]ColumnsStatement, 0)
+	if err := p.db.Raw(sqlPgSelectColumns, p.schema, name).Scan(&table.Columns).Error; err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL has no COLUMN_KEY, derive it from the indexes
+	indexes, err := p.DescribeIndexes(name)
+	if err != nil {
+		return nil, err
+	}
+	keys := columnKeys(indexes)
+	for i := range table.Columns {
+		table.Columns[i].Key = keys[strings.ToLower(table.Columns[i].Field)]
+	}
+	return table, nil
+}
+
+func (p *postgresIntrospector) DescribeIndexes(name string) ([]IndexStatement, error) {
+	rows := make([]indexColumnRow, 0)
+	if err := p.db.Raw(sqlPgSelectIndexes, p.schema, name).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return groupIndexRows(rows), nil
+}
+
+func (p *postgresIntrospector) DescribeForeignKeys(name string) ([]ForeignKeyStatement, error) {
+	rows := make([]foreignKeyColumnRow, 0)
+	if err := p.db.Raw(sqlPgSelectForeignKeys, p.schema, name).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return groupForeignKeyRows(rows), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestPostgresQueryColumns(t *testing.T) {
+	alias := regexp.MustCompile(`AS "(\w+)"`)
+	tests := []struct {
+		name    string
+		query   string
+		dest    any
+		derived []string // fields set from other queries
+	}{
+		{"sqlPgSelectTable", sqlPgSelectTable, &TableStatus{}, nil},
+		{"sqlPgSelectColumns", sqlPgSelectColumns, &ColumnsStatement{}, []string{"key"}},
+		{"sqlPgSelectIndexes", sqlPgSelectIndexes, &indexColumnRow{}, nil},
+		{"sqlPgSelectForeignKeys", sqlPgSelectForeignKeys, &foreignKeyColumnRow{}, nil},
+	}
+	for _, tt := range tests {
+		s, err := schema.Parse(tt.dest, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanned := make(map[string]bool)
+		for _, m := range alias.FindAllStringSubmatch(tt.query, -1) {
+			if s.LookUpField(m[1]) == nil {
+				t.Errorf("%s: column %s is not scanned into %T", tt.name, m[1], tt.dest)
+			}
+			scanned[m[1]] = true
+		}
+		for _, f := range s.Fields {
+			if f.DBName != "" && !scanned[f.DBName] && !slices.Contains(tt.derived, f.DBName) {
+				t.Errorf("%s: %T.%s is not selected", tt.name, tt.dest, f.Name)
+			}
+		}
+	}
+}
+
+func TestPostgresIndexRows(t *testing.T) {
+	// rows in the order of sqlPgSelectIndexes, the primary key first
+	rows := []indexColumnRow{
+		{Name: "tb_user_pkey", Primary: true, ColumnName: "tenant_id"},
+		{Name: "tb_user_pkey", Primary: true, ColumnName: "id"},
+		{Name: "idx_email", ColumnName: "Email"},
+		{Name: "idx_lower_name", ColumnName: ""},
+		{Name: "idx_name_ctime", NonUnique: true, ColumnName: "name"},
+		{Name: "idx_name_ctime", NonUnique: true, ColumnName: "ctime"},
+		{Name: "idx_tenant_name", NonUnique: true, ColumnName: "tenant_id"},
+	}
+	indexes := groupIndexRows(rows)
+	wantIndexes := []IndexStatement{
+		{Name: "tb_user_pkey", Primary: true, Unique: true, Columns: []string{"tenant_id", "id"}},
+		{Name: "idx_email", Unique: true, Columns: []string{"Email"}},
+		{Name: "idx_lower_name", Unique: true, Columns: []string{}},
+		{Name: "idx_name_ctime", Columns: []string{"name", "ctime"}},
+		{Name: "idx_tenant_name", Columns: []string{"tenant_id"}},
+	}
+	if !slices.EqualFunc(indexes, wantIndexes, equalIndex) {
+		t.Errorf("groupIndexRows() = %+v, want %+v", indexes, wantIndexes)
+	}
+	wantKeys := map[string]string{"tenant_id": "PRI", "id": "PRI", "email": "UNI", "name": "MUL"}
+	if keys := columnKeys(indexes); !maps.Equal(keys, wantKeys) {
+		t.Errorf("columnKeys() = %v, want %v", keys, wantKeys)
+	}
+
+	foreignKeys := groupForeignKeyRows([]foreignKeyColumnRow{
+		{Name: "fk_order_user", ColumnName: "tenant_id", ReferencedTable: "tb_user", ReferencedColumn: "tenant_id"},
+		{Name: "fk_order_user", ColumnName: "user_id", ReferencedTable: "tb_user", ReferencedColumn: "id"},
+		{Name: "fk_order_shop", ColumnName: "shop_id", ReferencedTable: "tb_shop", ReferencedColumn: "id"},
+	})
+	wantForeignKeys := []ForeignKeyStatement{
+		{Name: "fk_order_user", Columns: []string{"tenant_id", "user_id"}, ReferencedTable: "tb_user", ReferencedColumns: []string{"tenant_id", "id"}},
+		{Name: "fk_order_shop", Columns: []string{"shop_id"}, ReferencedTable: "tb_shop", ReferencedColumns: []string{"id"}},
+	}
+	if !slices.EqualFunc(foreignKeys, wantForeignKeys, equalForeignKey) {
+		t.Errorf("groupForeignKeyRows() = %+v, want %+v", foreignKeys, wantForeignKeys)
+	}
+}
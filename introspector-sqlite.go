@@ -0,0 +1,259 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	sqlSqliteListTables = "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+
+	sqlSqliteTableExists = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+
+	sqlSqliteTableInfo = "SELECT cid, name, type, \"notnull\", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid"
+
+	sqlSqliteIndexList = "SELECT name, \"unique\", origin FROM pragma_index_list(?) ORDER BY name"
+
+	sqlSqliteIndexInfo = "SELECT IFNULL(name, '') FROM pragma_index_info(?) ORDER BY seqno"
+
+	sqlSqliteForeignKeyList = "SELECT id, \"table\", \"from\", IFNULL(\"to\", '') FROM pragma_foreign_key_list(?) ORDER BY id, seq"
+)
+
+// sqliteIntrospector reads tables from a SQLite database file by pragma functions
+type sqliteIntrospector struct {
+	db   *sql.DB
+	file string
+}
+
+// newSqliteIntrospector opens the SQLite database file -d read only.
+func newSqliteIntrospector() (*sqliteIntrospector, error) {
+	if _, err := os.Stat(schemaName); err != nil {
+		return nil, err
+	}
+	fmt.Printf("sqlite: %s\n\n", schemaName)
+	db, err := sql.Open("sqlite3", "file:"+schemaName+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteIntrospector{db: db, file: schemaName}, nil
+}
+
+func (s *sqliteIntrospector) ListTables() ([]string, error) {
+	rows, err := s.db.Query(sqlSqliteListTables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (s *sqliteIntrospector) DescribeTable(name string) (*Table, error) {
+	var count int
+	if err := s.db.QueryRow(sqlSqliteTableExists, name).Scan(&count); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%s: table %s not found", s.file, name)
+	}
+
+	columns, pk, err := s.tableInfo(name)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := s.DescribeIndexes(name)
+	if err != nil {
+		return nil, err
+	}
+	keys := columnKeys(indexes)
+	for i := range columns {
+		col := &columns[i]
+		col.Key = keys[strings.ToLower(col.Field)]
+		// INTEGER PRIMARY KEY is an alias of the auto increment rowid
+		if len(pk) == 1 && strings.EqualFold(pk[0], col.Field) && col.DataType == "integer" {
+			col.Extra = "auto_increment"
+		}
+	}
+	return &Table{Status: TableStatus{Name: name}, Columns: columns}, nil
+}
+
+// tableInfo returns the columns of a table and the primary key columns in key order.
+func (s *sqliteIntrospector) tableInfo(name string) ([]ColumnsStatement, []string, error) {
+	rows, err := s.db.Query(sqlSqliteTableInfo, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns := make([]ColumnsStatement, 0)
+	pkOrder := make(map[int]string)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var field, columnType string
+		var defaultValue sql.NullString
+		if err = rows.Scan(&cid, &field, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, nil, err
+		}
+		col := ColumnsStatement{
+			Field:           field,
+			Type:            strings.ToLower(columnType),
+			Null:            "YES",
+			HasDefault:      defaultValue.Valid && !strings.EqualFold(defaultValue.String, "NULL"),
+			OrdinalPosition: cid + 1,
+		}
+		// DEFAULT NULL is no default, the same as information_schema reports it
+		if col.HasDefault {
+			col.Default = sqliteUnquote(defaultValue.String)
+		}
+		col.DataType, _, _ = strings.Cut(col.Type, "(")
+		col.DataType = strings.TrimSpace(col.DataType)
+		col.Unsigned = strings.Contains(col.Type, "unsigned")
+		if notNull == 1 || pk > 0 {
+			col.Null = "NO"
+		}
+		if pk > 0 {
+			pkOrder[pk] = field
+		}
+		completeColumnType(&col)
+		columns = append(columns, col)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	order := make([]int, 0, len(pkOrder))
+	for k := range pkOrder {
+		order = append(order, k)
+	}
+	sort.Ints(order)
+	pk := make([]string, 0, len(order))
+	for _, k := range order {
+		pk = append(pk, pkOrder[k])
+	}
+	return columns, pk, nil
+}
+
+func (s *sqliteIntrospector) DescribeIndexes(name string) ([]IndexStatement, error) {
+	_, pk, err := s.tableInfo(name)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]IndexStatement, 0)
+	if len(pk) > 0 {
+		// the rowid primary key has no index in index_list
+		indexes = append(indexes, IndexStatement{Name: "PRIMARY", Primary: true, Unique: true, Columns: pk})
+	}
+
+	type indexRow struct {
+		name   string
+		unique bool
+		origin string
+	}
+	rows, err := s.db.Query(sqlSqliteIndexList, name)
+	if err != nil {
+		return nil, err
+	}
+	indexRows := make([]indexRow, 0)
+	for rows.Next() {
+		var r indexRow
+		if err = rows.Scan(&r.name, &r.unique, &r.origin); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		indexRows = append(indexRows, r)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range indexRows {
+		if r.origin == "pk" {
+			continue
+		}
+		columns, err := s.indexColumns(r.name)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, IndexStatement{Name: r.name, Unique: r.unique, Columns: columns})
+	}
+	return indexes, nil
+}
+
+// indexColumns returns the columns of an index, expression key parts are dropped.
+func (s *sqliteIntrospector) indexColumns(index string) ([]string, error) {
+	rows, err := s.db.Query(sqlSqliteIndexInfo, index)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make([]string, 0)
+	for rows.Next() {
+		var column string
+		if err = rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns, rows.Err()
+}
+
+func (s *sqliteIntrospector) DescribeForeignKeys(name string) ([]ForeignKeyStatement, error) {
+	rows, err := s.db.Query(sqlSqliteForeignKeyList, name)
+	if err != nil {
+		return nil, err
+	}
+	fkRows := make([]foreignKeyColumnRow, 0)
+	for rows.Next() {
+		var id int
+		var r foreignKeyColumnRow
+		if err = rows.Scan(&id, &r.ReferencedTable, &r.ColumnName, &r.ReferencedColumn); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		// SQLite foreign keys are anonymous
+		r.Name = fmt.Sprintf("fk_%s_%d", name, id)
+		fkRows = append(fkRows, r)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	foreignKeys := groupForeignKeyRows(fkRows)
+	for i := range foreignKeys {
+		fk := &foreignKeys[i]
+		if fk.ReferencedColumns[0] != "" {
+			continue
+		}
+		// REFERENCES parent without columns refers to the primary key of the parent
+		_, pk, err := s.tableInfo(fk.ReferencedTable)
+		if err != nil {
+			return nil, err
+		}
+		copy(fk.ReferencedColumns, pk)
+	}
+	return foreignKeys, nil
+}
+
+// sqliteUnquote returns the value of a quoted string default, other defaults are returned as is.
+func sqliteUnquote(s string) string {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
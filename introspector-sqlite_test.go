@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// openSqlite creates a SQLite database file from DDL statements and returns an introspector reading it.
+func openSqlite(t *testing.T, statements ...string) *sqliteIntrospector {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite3", "file:"+file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	for _, statement := range statements {
+		if _, err = db.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	return &sqliteIntrospector{db: db, file: file}
+}
+
+func TestSqliteIntrospector(t *testing.T) {
+	s := openSqlite(t,
+		"CREATE TABLE tb_user (id INTEGER PRIMARY KEY, name VARCHAR(32) NOT NULL DEFAULT 'it''s', "+
+			"email TEXT UNIQUE, score REAL DEFAULT NULL, ctime DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP)",
+		"CREATE INDEX idx_name_email ON tb_user (name, email)",
+		"CREATE INDEX idx_lower_name ON tb_user (lower(name))",
+		"CREATE TABLE tb_user_role (user_id INTEGER NOT NULL REFERENCES tb_user, role_id BIGINT NOT NULL, "+
+			"PRIMARY KEY (user_id, role_id))",
+	)
+
+	names, err := s.ListTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"tb_user", "tb_user_role"}; !slices.Equal(names, want) {
+		t.Errorf("ListTables() = %v, want %v", names, want)
+	}
+
+	table, err := readTable(s, "tb_user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantColumns := []ColumnsStatement{
+		{Field: "id", Type: "integer", Null: "NO", Key: "PRI", Extra: "auto_increment", DataType: "integer", NumericPrecision: 10, OrdinalPosition: 1},
+		{Field: "name", Type: "varchar(32)", Null: "NO", Key: "MUL", Default: "it's", HasDefault: true, DataType: "varchar", CharMaxLength: 32, OrdinalPosition: 2},
+		{Field: "email", Type: "text", Null: "YES", Key: "UNI", DataType: "text", CharMaxLength: 65535, OrdinalPosition: 3},
+		{Field: "score", Type: "real", Null: "YES", DataType: "real", NumericPrecision: 22, OrdinalPosition: 4},
+		{Field: "ctime", Type: "datetime", Null: "NO", Default: "CURRENT_TIMESTAMP", HasDefault: true, DataType: "datetime", OrdinalPosition: 5},
+	}
+	if table.Status.Name != "tb_user" || !slices.Equal(table.Columns, wantColumns) {
+		t.Errorf("tb_user = %+v %+v,\nwant columns %+v", table.Status, table.Columns, wantColumns)
+	}
+	wantIndexes := []IndexStatement{
+		{Name: "PRIMARY", Primary: true, Unique: true, Columns: []string{"id"}},
+		{Name: "idx_lower_name", Columns: []string{}},
+		{Name: "idx_name_email", Columns: []string{"name", "email"}},
+		{Name: "sqlite_autoindex_tb_user_1", Unique: true, Columns: []string{"email"}},
+	}
+	if !slices.EqualFunc(table.Indexes, wantIndexes, equalIndex) {
+		t.Errorf("tb_user indexes = %+v, want %+v", table.Indexes, wantIndexes)
+	}
+
+	table, err = readTable(s, "tb_user_role")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(table.Columns))
+	for _, col := range table.Columns {
+		keys = append(keys, col.Field+" "+col.Key+" "+col.Extra)
+	}
+	// only the single INTEGER PRIMARY KEY is the rowid
+	if want := []string{"user_id PRI ", "role_id PRI "}; !slices.Equal(keys, want) {
+		t.Errorf("tb_user_role keys = %q, want %q", keys, want)
+	}
+	wantForeignKeys := []ForeignKeyStatement{
+		{Name: "fk_tb_user_role_0", Columns: []string{"user_id"}, ReferencedTable: "tb_user", ReferencedColumns: []string{"id"}},
+	}
+	if !slices.EqualFunc(table.ForeignKeys, wantForeignKeys, equalForeignKey) {
+		t.Errorf("tb_user_role foreign keys = %+v, want %+v", table.ForeignKeys, wantForeignKeys)
+	}
+
+	if _, err = s.DescribeTable("tb_missing"); err == nil {
+		t.Error("DescribeTable(tb_missing) succeeded, want an error")
+	}
+}
+
+func equalForeignKey(a, b ForeignKeyStatement) bool {
+	return a.Name == b.Name && a.ReferencedTable == b.ReferencedTable &&
+		slices.Equal(a.Columns, b.Columns) && slices.Equal(a.ReferencedColumns, b.ReferencedColumns)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dialectMySQL    = "mysql"
+	dialectPostgres = "postgres"
+	dialectSqlite   = "sqlite"
+)
+
+// Introspector reads table definitions from a database or any other schema source
+type Introspector interface {
+	// ListTables returns the names of every base table.
+	ListTables() ([]string, error)
+	// DescribeTable returns the table status and columns, Indexes and ForeignKeys are left empty.
+	DescribeTable(name string) (*Table, error)
+	// DescribeIndexes returns the indexes of a table, the primary key included.
+	DescribeIndexes(name string) ([]IndexStatement, error)
+	// DescribeForeignKeys returns the foreign key constraints of a table.
+	DescribeForeignKeys(name string) ([]ForeignKeyStatement, error)
+}
+
+// newIntrospector returns the introspector of the schema source selected by flags.
+func newIntrospector() (Introspector, error) {
+	if snapshotFile != "" {
+		return newSnapshotIntrospector(snapshotFile)
+	}
+	if ddlFile != "" {
+		return newDDLIntrospector(ddlFile)
+	}
+	switch dialect {
+	case dialectMySQL:
+		return newMySQLIntrospector()
+	case dialectPostgres:
+		return newPostgresIntrospector()
+	case dialectSqlite:
+		return newSqliteIntrospector()
+	}
+	return nil, fmt.Errorf("unsupported dialect %s", dialect)
+}
+
+// memoryIntrospector serves tables already loaded from a DDL file or a snapshot
+type memoryIntrospector struct {
+	source string
+	tables []*Table
+}
+
+func (m *memoryIntrospector) ListTables() ([]string, error) {
+	names := make([]string, 0, len(m.tables))
+	for _, t := range m.tables {
+		names = append(names, t.Status.Name)
+	}
+	return names, nil
+}
+
+func (m *memoryIntrospector) DescribeTable(name string) (*Table, error) {
+	t, err := m.find(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Table{Status: t.Status, Columns: t.Columns}, nil
+}
+
+func (m *memoryIntrospector) DescribeIndexes(name string) ([]IndexStatement, error) {
+	t, err := m.find(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.Indexes, nil
+}
+
+func (m *memoryIntrospector) DescribeForeignKeys(name string) ([]ForeignKeyStatement, error) {
+	t, err := m.find(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.ForeignKeys, nil
+}
+
+func (m *memoryIntrospector) find(name string) (*Table, error) {
+	for _, t := range m.tables {
+		if t.Status.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: table %s is not defined", m.source, name)
+}
+
+// readTable returns a table with its columns, indexes and foreign keys.
+func readTable(in Introspector, name string) (*Table, error) {
+	table, err := in.DescribeTable(name)
+	if err != nil {
+		return nil, err
+	}
+	if table.Indexes, err = in.DescribeIndexes(name); err != nil {
+		return nil, err
+	}
+	if table.ForeignKeys, err = in.DescribeForeignKeys(name); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// indexColumnRow is a row of an index query, one row per indexed column
+type indexColumnRow struct {
+	Name       string
+	Primary    bool
+	NonUnique  bool
+	ColumnName string
+}
+
+// foreignKeyColumnRow is a row of a foreign key query, one row per referencing column
+type foreignKeyColumnRow struct {
+	Name             string
+	ColumnName       string
+	ReferencedTable  string
+	ReferencedColumn string
+}
+
+// groupIndexRows merges consecutive rows of the same index, expression key parts without a column are dropped.
+func groupIndexRows(rows []indexColumnRow) []IndexStatement {
+	indexes := make([]IndexStatement, 0)
+	for _, row := range rows {
+		n := len(indexes)
+		if n == 0 || indexes[n-1].Name != row.Name {
+			indexes = append(indexes, IndexStatement{
+				Name:    row.Name,
+				Primary: row.Primary,
+				Unique:  !row.NonUnique,
+				Columns: make([]string, 0),
+			})
+			n++
+		}
+		if row.ColumnName != "" {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, row.ColumnName)
+		}
+	}
+	return indexes
+}
+
+// groupForeignKeyRows merges consecutive rows of the same foreign key.
+func groupForeignKeyRows(rows []foreignKeyColumnRow) []ForeignKeyStatement {
+	foreignKeys := make([]ForeignKeyStatement, 0)
+	for _, row := range rows {
+		n := len(foreignKeys)
+		if n == 0 || foreignKeys[n-1].Name != row.Name {
+			foreignKeys = append(foreignKeys, ForeignKeyStatement{Name: row.Name, ReferencedTable: row.ReferencedTable})
+			n++
+		}
+		fk := &foreignKeys[n-1]
+		fk.Columns = append(fk.Columns, row.ColumnName)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, row.ReferencedColumn)
+	}
+	return foreignKeys
+}
+
+// columnKeys returns the ColumnsStatement.Key of every indexed column the same way MySQL reports PRI, UNI and MUL,
+// keyed by the lower case column name.
+func columnKeys(indexes []IndexStatement) map[string]string {
+	keys := make(map[string]string)
+	for _, index := range indexes {
+		if len(index.Columns) == 0 {
+			continue
+		}
+		for _, column := range index.Columns {
+			if index.Primary {
+				keys[strings.ToLower(column)] = "PRI"
+			}
+		}
+		first := strings.ToLower(index.Columns[0])
+		if index.Primary || keys[first] == "PRI" {
+			continue
+		}
+		if index.Unique && len(index.Columns) == 1 {
+			keys[first] = "UNI"
+		} else if keys[first] == "" {
+			keys[first] = "MUL"
+		}
+	}
+	return keys
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// javaEnum defines a Java enum generated for a column with a fixed set of values
+type javaEnum struct {
+	ClassName string
+	Column    ColumnsStatement
+	CodeType  string // Java type of the code stored in the column
+	Set       bool   // the column holds any combination of the values, e.g. a MySQL SET
+	Constants []javaEnumConstant
+}
+
+// javaEnumConstant defines a constant of a Java enum
+type javaEnumConstant struct {
+	Name string
+	Code string
+	Desc string
+}
+
+// PackageName returns the fully qualified name of the enum.
+func (e *javaEnum) PackageName() string {
+	return layerPackages().Enum + "." + e.ClassName
+}
+
+// TypeHandlerClassName returns the MyBatis type handler of a set enum.
+func (e *javaEnum) TypeHandlerClassName() string {
+	return e.ClassName + "TypeHandler"
+}
+
+// ConverterClassName returns the JPA attribute converter of an enum.
+func (e *javaEnum) ConverterClassName() string {
+	return e.ClassName + "Converter"
+}
+
+// parseJavaEnums returns the enums of the ENUM and SET columns of a table and of the integer columns
+// documenting their codes in the comment or in the enum hints of the table, the matching javaFields are typed
+// as the enum, or as an EnumSet of it for SET columns. An empty hint leaves the column alone.
+func parseJavaEnums(table *Table, javaFields []JavaField) []*javaEnum {
+	entityClassName := entityClassNameOf(table.Status.Name)
+	enums := make([]*javaEnum, 0)
+	hints := tableConfig(table.Status.Name).Enums
+	for i, col := range table.Columns {
+		hint, hinted := hints[col.Field]
+		if hinted && hint == "" {
+			continue
+		}
+		dataType := strings.ToLower(col.DataType)
+		if dataType != "enum" && dataType != "set" {
+			if hinted {
+				col.Comment = hint
+			}
+			if e := parseCommentJavaEnum(entityClassName, col, javaFields[i]); e != nil {
+				enums = append(enums, e)
+				typeJavaField(&javaFields[i], e)
+			}
+			continue
+		}
+		literals := parseTypeLiterals(col.Type)
+		if len(literals) == 0 {
+			continue
+		}
+		e := &javaEnum{
+			ClassName: entityClassName + firstUpCase(javaFields[i].Field),
+			Column:    col,
+			CodeType:  "String",
+			Set:       dataType == "set",
+		}
+		names := make(map[string]bool)
+		for j, literal := range literals {
+			name := uniqueJavaEnumConstantName(names, javaEnumConstantName(literal, fmt.Sprintf("VALUE_%d", j)))
+			e.Constants = append(e.Constants, javaEnumConstant{Name: name, Code: literal})
+		}
+		enums = append(enums, e)
+		typeJavaField(&javaFields[i], e)
+	}
+	return enums
+}
+
+// parseCommentJavaEnum returns the enum of the codes documented in the comment of an integer column,
+// or nil if the comment documents none.
+func parseCommentJavaEnum(entityClassName string, col ColumnsStatement, f JavaField) *javaEnum {
+	switch f.JavaType {
+	case "Integer", "Long", "Short", "Byte":
+	default:
+		return nil
+	}
+	if f.IsPri {
+		return nil
+	}
+	constants := parseCommentEnum(col.Comment)
+	if len(constants) == 0 {
+		return nil
+	}
+	names := make(map[string]bool)
+	for i := range constants {
+		constants[i].Name = uniqueJavaEnumConstantName(names, commentEnumConstantName(constants[i]))
+	}
+	return &javaEnum{
+		ClassName: entityClassName + firstUpCase(f.Field),
+		Column:    col,
+		CodeType:  f.JavaType,
+		Constants: constants,
+	}
+}
+
+// Described reports whether the constants of an enum carry a description.
+func (e *javaEnum) Described() bool {
+	for _, c := range e.Constants {
+		if c.Desc != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstantArgs returns the constructor arguments of a constant, e.g. "active" or 0, "待审核".
+func (e *javaEnum) ConstantArgs(c javaEnumConstant) string {
+	args := c.Code
+	switch e.CodeType {
+	case "String":
+		args = javaStringLiteral(c.Code)
+	case "Long":
+		args += "L"
+	case "Short", "Byte":
+		args = fmt.Sprintf("(%s) %s", strings.ToLower(e.CodeType), args)
+	}
+	if e.Described() {
+		args += ", " + javaStringLiteral(c.Desc)
+	}
+	return args
+}
+
+// typeJavaField makes a Java field hold the values of an enum.
+func typeJavaField(f *JavaField, e *javaEnum) {
+	f.JavaType, f.PackageName, f.Imports = e.ClassName, e.PackageName(), nil
+	if e.Set {
+		f.JavaType = fmt.Sprintf("EnumSet<%s>", e.ClassName)
+		f.Imports = []string{"java.util.EnumSet"}
+	}
+}
+
+// uniqueJavaEnumConstantName returns name, suffixed by a number when it is already taken.
+func uniqueJavaEnumConstantName(names map[string]bool, name string) string {
+	unique := name
+	for n := 2; names[unique]; n++ {
+		unique = fmt.Sprintf("%s_%d", name, n)
+	}
+	names[unique] = true
+	return unique
+}
+
+// javaEnumConstantName returns an upper snake case constant name of a value,
+// values without any ASCII letter or digit are named fallback, e.g. VALUE_0.
+func javaEnumConstantName(value string, fallback string) string {
+	var b strings.Builder
+	for _, c := range value {
+		switch {
+		case 'a' <= c && c <= 'z':
+			b.WriteRune(c - ('a' - 'A'))
+		case 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+			b.WriteRune(c)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteByte('_')
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "_")
+	if name == "" {
+		return fallback
+	}
+	if '0' <= name[0] && name[0] <= '9' {
+		return "VALUE_" + name
+	}
+	return name
+}
+
+// poJavaFields returns the PO members of javaFields, renamed fields name their column
+// and SET columns are mapped through their type handler.
+func poJavaFields(javaFields []JavaField, enums []*javaEnum) (fields []JavaField, autoResultMap bool) {
+	fields = append([]JavaField{}, javaFields...)
+	for i := range fields {
+		args, imports := make([]string, 0), make([]string, 0)
+		if fields[i].Column != "" && fields[i].Field != camelCase(fields[i].Column) {
+			args = append(args, javaStringLiteral(fields[i].Column))
+		}
+		for _, e := range enums {
+			if e.Set && e.Column.Field == fields[i].Column {
+				args = append(args, fmt.Sprintf("typeHandler = %s.class", e.TypeHandlerClassName()))
+				imports = append(imports, layerPackages().Handler+"."+e.TypeHandlerClassName())
+				autoResultMap = true
+			}
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if len(args) > 1 {
+			args[0] = "value = " + args[0]
+		}
+		fields[i].Imports = append(append(append([]string{}, fields[i].Imports...), "com.baomidou.mybatisplus.annotation.TableField"), imports...)
+		fields[i].Annotations = []string{fmt.Sprintf("@TableField(%s)", strings.Join(args, ", "))}
+	}
+	return fields, autoResultMap
+}
+
+// parseEnumFactoryCodes returns the imports and the methods converting codes into the described enums of a table,
+// unknown codes are logged rather than failing the conversion.
+func parseEnumFactoryCodes(enums []*javaEnum) (imports []string, codes string) {
+	var b strings.Builder
+	for _, e := range enums {
+		if e.Set || !e.Described() {
+			continue
+		}
+		imports = append(imports, e.PackageName())
+		b.WriteString(fmt.Sprintf("\n  public static %s to%s(%s code) {\n", e.ClassName, e.ClassName, e.CodeType))
+		b.WriteString("    if (code == null) {\n      return null;\n    }\n")
+		b.WriteString(fmt.Sprintf("    var value = %s.of(code);\n", e.ClassName))
+		b.WriteString(fmt.Sprintf("    if (value == null) {\n      log.warn(\"unknown %s code {}\", code);\n    }\n", e.ClassName))
+		b.WriteString("    return value;\n  }\n")
+	}
+	return imports, b.String()
+}
+
+// javaStringLiteral quotes a value as a Java string literal.
+func javaStringLiteral(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
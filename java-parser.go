@@ -2,10 +2,13 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
-func parseJavaImportsAndFields(javaFields []JavaField) (importCodes, fieldCodes string) {
+// parseJavaImportsAndFields returns the import lines and the aligned member lines of javaFields,
+// members named in skipped are left out but still contribute their imports.
+func parseJavaImportsAndFields(javaFields []JavaField, skipped ...string) (importCodes, fieldCodes string) {
 	maxTypeStringLen := 0
 	maxFieldStringLen := 0
 	for _, v := range javaFields {
@@ -19,12 +22,17 @@ func parseJavaImportsAndFields(javaFields []JavaField) (importCodes, fieldCodes
 
 	importCodes, fieldCodes = "", ""
 	for _, v := range javaFields {
-		if v.PackageName != "" && !strings.Contains(importCodes, v.PackageName) {
-			importCodes += fmt.Sprintf("import %s;\n", v.PackageName)
+		for _, pkg := range append([]string{v.PackageName}, v.Imports...) {
+			if pkg != "" && !strings.Contains(importCodes, "import "+pkg+";") {
+				importCodes += fmt.Sprintf("import %s;\n", pkg)
+			}
 		}
-		if v.Field == "id" || v.Field == "ctime" || v.Field == "mtime" {
+		if slices.Contains(skipped, v.Field) {
 			continue
 		}
+		for _, annotation := range v.Annotations {
+			fieldCodes += fmt.Sprintf("  %s\n", annotation)
+		}
 		fieldCodes += fmt.Sprintf("  private %s %s;%s// %s\n", v.JavaType+strings.Repeat(" ", maxTypeStringLen-len(v.JavaType)), v.Field, strings.Repeat(" ", maxFieldStringLen-len(v.Field)+1), v.Comment)
 	}
 	importCodes = strings.TrimSuffix(importCodes, "\n")
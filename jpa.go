@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseJpaPoCodes returns the imports and members of the JPA entity of a table. Every column is declared since the
+// base PO is a MyBatis-Plus class, a composite key, or every column of a table without primary key, forms the
+// Key class the entity names in @IdClass.
+func parseJpaPoCodes(table *Table, javaFields []JavaField, enums []*javaEnum) poCodes {
+	keyed := jpaKeyed(javaFields)
+	persistence := eeNamespace() + ".persistence."
+	imports := []string{persistence + "Column", persistence + "Entity", persistence + "Table", "lombok.Data"}
+	fields := slices.Clone(javaFields)
+	keyFields := make([]JavaField, 0)
+	for i, col := range table.Columns {
+		f := &fields[i]
+		annotations := make([]string, 0)
+		if f.IsPri || !keyed {
+			annotations = append(annotations, "@Id")
+			imports = append(imports, persistence+"Id")
+			keyFields = append(keyFields, JavaField{JavaType: f.JavaType, Field: f.Field, Comment: f.Comment, PackageName: f.PackageName, Imports: f.Imports})
+		}
+		if strings.Contains(strings.ToLower(col.Extra), "auto_increment") {
+			annotations = append(annotations, "@GeneratedValue(strategy = GenerationType.IDENTITY)")
+			imports = append(imports, persistence+"GeneratedValue", persistence+"GenerationType")
+		}
+		if isLobColumn(col) {
+			annotations = append(annotations, "@Lob")
+			imports = append(imports, persistence+"Lob")
+		}
+		for _, e := range enums {
+			if e.Column.Field == col.Field {
+				annotations = append(annotations, fmt.Sprintf("@Convert(converter = %s.class)", e.ConverterClassName()))
+				imports = append(imports, persistence+"Convert", layerPackages().Handler+"."+e.ConverterClassName())
+			}
+		}
+		annotations = append(annotations, jpaColumnAnnotation(col))
+		f.Annotations = annotations
+		imports = append(append(imports, f.PackageName), f.Imports...)
+	}
+
+	codes := poCodes{}
+	_, codes.Fields = parseJavaImportsAndFields(fields)
+	if len(keyFields) > 1 {
+		imports = append(imports, "java.io.Serializable", persistence+"IdClass", "lombok.AllArgsConstructor", "lombok.NoArgsConstructor")
+		_, keyCodes := parseJavaImportsAndFields(keyFields)
+		codes.Key = "  " + strings.ReplaceAll(keyCodes, "\n", "\n  ")
+	}
+	imports = slices.DeleteFunc(imports, func(pkg string) bool { return pkg == "" })
+	codes.Imports = sortJavaImports(imports)
+	return codes
+}
+
+// jpaColumnAnnotation returns the @Column of a column, with the length of character columns,
+// the precision and scale of decimals and nullable = false for NOT NULL columns.
+func jpaColumnAnnotation(col ColumnsStatement) string {
+	args := []string{"name = " + javaStringLiteral(col.Field)}
+	dataType := strings.ToLower(col.DataType)
+	switch {
+	case isLobColumn(col):
+	case col.CharMaxLength > 0 && (strings.Contains(dataType, "char") || strings.Contains(dataType, "binary")):
+		args = append(args, fmt.Sprintf("length = %d", col.CharMaxLength))
+	case (dataType == "decimal" || dataType == "numeric") && col.NumericPrecision > 0:
+		args = append(args, fmt.Sprintf("precision = %d", col.NumericPrecision), fmt.Sprintf("scale = %d", col.NumericScale))
+	}
+	if strings.EqualFold(col.Null, "NO") {
+		args = append(args, "nullable = false")
+	}
+	return fmt.Sprintf("@Column(%s)", strings.Join(args, ", "))
+}
+
+// isLobColumn reports whether a column holds a large text or binary object.
+func isLobColumn(col ColumnsStatement) bool {
+	dataType := strings.ToLower(col.DataType)
+	return strings.Contains(dataType, "text") || strings.Contains(dataType, "blob") ||
+		strings.Contains(dataType, "clob") || dataType == "bytea"
+}
+
+// jpaKeyed reports whether a table has a primary key, the columns of a table without one form its key.
+func jpaKeyed(javaFields []JavaField) bool {
+	return slices.ContainsFunc(javaFields, func(f JavaField) bool { return f.IsPri })
+}
+
+// jpaIdType returns the id type of the JPA repository of a table with the imports it needs,
+// the Key class of the entity for a composite key.
+func jpaIdType(javaFields []JavaField, poClassName string) (string, []string) {
+	keys := slices.Clone(javaFields)
+	if jpaKeyed(javaFields) {
+		keys = slices.DeleteFunc(keys, func(f JavaField) bool { return !f.IsPri })
+	}
+	if len(keys) == 1 {
+		return keys[0].JavaType, append([]string{keys[0].PackageName}, keys[0].Imports...)
+	}
+	return poClassName + ".Key", nil
+}
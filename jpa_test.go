@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJpaRepositoryIdType(t *testing.T) {
+	defer func(t, d string) { target, dialect = t, d }(target, dialect)
+	target = targetJpa
+	tests := []struct {
+		dialect string
+		table   *Table
+		file    string
+		want    []string
+	}{
+		{
+			dialectMySQL,
+			parseTable(t, "CREATE TABLE tb_user (id bigint unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(32))"),
+			"user/infrastructure/persistence/mapper/UserJpaRepository.java",
+			[]string{"import java.math.BigInteger;", "extends JpaRepository<UserPo, BigInteger>"},
+		},
+		{
+			dialectPostgres,
+			&Table{
+				Status:  TableStatus{Name: "tb_device"},
+				Columns: []ColumnsStatement{{Field: "id", Type: "uuid", DataType: "uuid", Null: "NO", Key: "PRI"}, {Field: "name", Type: "text", DataType: "text", Null: "YES"}},
+				Indexes: []IndexStatement{{Name: "tb_device_pkey", Primary: true, Unique: true, Columns: []string{"id"}}},
+			},
+			"user/infrastructure/persistence/mapper/DeviceJpaRepository.java",
+			[]string{"import java.util.UUID;", "extends JpaRepository<DevicePo, UUID>"},
+		},
+		{
+			dialectMySQL,
+			parseTable(t, "CREATE TABLE tb_user_role (user_id bigint unsigned NOT NULL, role_id int NOT NULL, PRIMARY KEY (user_id, role_id))"),
+			"user/infrastructure/persistence/mapper/UserRoleJpaRepository.java",
+			[]string{"extends JpaRepository<UserRolePo, UserRolePo.Key>"},
+		},
+	}
+	for _, tt := range tests {
+		dialect = tt.dialect
+		codes, ok := renderTable(t, tt.table)[tt.file]
+		if !ok {
+			t.Errorf("%s was not generated", tt.file)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(codes, want) {
+				t.Errorf("%s does not contain %q:\n%s", tt.file, want, codes)
+			}
+		}
+	}
+}
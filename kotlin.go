@@ -0,0 +1,413 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	langJava   = "java"
+	langKotlin = "kotlin" // the templates under templates/kotlin replace the .java ones
+)
+
+// langs are the languages of the generated code
+var langs = []string{langJava, langKotlin}
+
+// lang is the language of the generated code, see -lang
+var lang = langJava
+
+// validateLang checks a language name.
+func validateLang(name string) error {
+	if !slices.Contains(langs, name) {
+		return fmt.Errorf("unknown lang %s, expected one of %s", name, strings.Join(langs, ", "))
+	}
+	return nil
+}
+
+// validateLangOptions checks the options the Kotlin templates support, they are written for the default target.
+func validateLangOptions() error {
+	if lang != langKotlin {
+		return nil
+	}
+	if target != targetMybatisPlus {
+		return fmt.Errorf("-lang kotlin supports -target %s only", targetMybatisPlus)
+	}
+	if generationGap {
+		return errors.New("-lang kotlin does not support -generation-gap, data classes cannot be extended")
+	}
+	return nil
+}
+
+// kotlinTypes maps the Java types having a Kotlin counterpart of another name
+var kotlinTypes = map[string]string{
+	"Integer":   "Int",
+	"Character": "Char",
+	"Object":    "Any",
+	"byte[]":    "ByteArray",
+	"int":       "Int",
+	"long":      "Long",
+	"short":     "Short",
+	"boolean":   "Boolean",
+	"double":    "Double",
+	"float":     "Float",
+}
+
+var javaTypeNamePattern = regexp.MustCompile(`[A-Za-z_$][\w.$]*(\[])?`)
+
+// kotlinType returns the Kotlin type of a Java type, type arguments included, e.g. List<Integer> -> List<Int>.
+func kotlinType(javaType string) string {
+	return javaTypeNamePattern.ReplaceAllStringFunc(javaType, func(name string) string {
+		if t, ok := kotlinTypes[name]; ok {
+			return t
+		}
+		return name
+	})
+}
+
+// kotlinStringLiteral quotes a value as a Kotlin string literal.
+func kotlinStringLiteral(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// KotlinCodeType returns the Kotlin type of the code of an enum.
+func (e *javaEnum) KotlinCodeType() string {
+	return kotlinType(e.CodeType)
+}
+
+// KotlinConstantArgs returns the constructor arguments of a constant in Kotlin, e.g. "active" or 0, "待审核".
+func (e *javaEnum) KotlinConstantArgs(c javaEnumConstant) string {
+	args := c.Code
+	switch e.CodeType {
+	case "String":
+		args = kotlinStringLiteral(c.Code)
+	case "Long":
+		args += "L"
+	}
+	if e.Described() {
+		args += ", " + kotlinStringLiteral(c.Desc)
+	}
+	return args
+}
+
+// sortKotlinImports returns import lines in the order of the generated templates, the types Kotlin imports by
+// default are left out.
+func sortKotlinImports(imports []string) string {
+	sorted := slices.DeleteFunc(slices.Clone(imports), func(pkg string) bool {
+		return pkg == "" || pkg == "java.util.List" || pkg == "java.util.Collection"
+	})
+	slices.Sort(sorted)
+	lines := make([]string, 0, len(sorted))
+	for _, v := range slices.Compact(sorted) {
+		lines = append(lines, "import "+v)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// kotlinPropertyType returns the Kotlin type of the property of a member, nullable for a nullable column.
+func kotlinPropertyType(f JavaField) string {
+	if f.Nullable {
+		return kotlinType(f.JavaType) + "?"
+	}
+	return kotlinType(f.JavaType)
+}
+
+// parseKotlinImportsAndProperties returns the imports and the aligned constructor properties of a data class,
+// nullable properties default to null and lists to an empty one. Members named in skipped are left out.
+func parseKotlinImportsAndProperties(javaFields []JavaField, skipped ...string) (imports []string, propertyCodes string) {
+	declarations := make([]string, len(javaFields))
+	maxLen := 0
+	for i, f := range javaFields {
+		if slices.Contains(skipped, f.Field) {
+			continue
+		}
+		imports = append(append(imports, f.PackageName), f.Imports...)
+		declarations[i] = fmt.Sprintf("var %s: %s", f.Field, kotlinPropertyType(f))
+		switch {
+		case f.Nullable:
+			declarations[i] += " = null"
+		case strings.HasPrefix(f.JavaType, "List<"):
+			declarations[i] += " = emptyList()"
+		}
+		declarations[i] += ","
+		maxLen = max(maxLen, len(declarations[i]))
+	}
+
+	var b strings.Builder
+	for i, f := range javaFields {
+		if declarations[i] == "" {
+			continue
+		}
+		for _, annotation := range f.Annotations {
+			b.WriteString(fmt.Sprintf("    %s\n", annotation))
+		}
+		b.WriteString(fmt.Sprintf("    %s%s// %s\n", declarations[i], strings.Repeat(" ", maxLen-len(declarations[i])+1), f.Comment))
+	}
+	return imports, strings.TrimSuffix(b.String(), "\n")
+}
+
+// parseKotlinPoCodes returns the imports and properties of a PO, renamed properties and EnumSet ones mapped
+// through a type handler carry a @TableField.
+func parseKotlinPoCodes(javaFields []JavaField, enums []*javaEnum) poCodes {
+	fields := slices.Clone(javaFields)
+	codes := poCodes{}
+	for i := range fields {
+		args := make([]string, 0)
+		if fields[i].Column != "" && fields[i].Field != camelCase(fields[i].Column) {
+			args = append(args, kotlinStringLiteral(fields[i].Column))
+		}
+		for _, e := range enums {
+			if e.Set && e.Column.Field == fields[i].Column {
+				args = append(args, fmt.Sprintf("typeHandler = %s::class", e.TypeHandlerClassName()))
+				fields[i].Imports = append(slices.Clone(fields[i].Imports), layerPackages().Handler+"."+e.TypeHandlerClassName())
+				codes.AutoResultMap = true
+			}
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if len(args) > 1 {
+			args[0] = "value = " + args[0]
+		}
+		fields[i].Imports = append(slices.Clone(fields[i].Imports), "com.baomidou.mybatisplus.annotation.TableField")
+		fields[i].Annotations = []string{fmt.Sprintf("@field:TableField(%s)", strings.Join(args, ", "))}
+	}
+	imports, properties := parseKotlinImportsAndProperties(fields, basePoFields()...)
+	imports = append(imports, "com.baomidou.mybatisplus.annotation.TableName", layout.Utils.BasePo)
+	codes.Imports, codes.Fields = sortKotlinImports(imports), properties
+	return codes
+}
+
+// parseKotlinEntityCodes returns the imports and properties of an entity, an aggregate root keeps its identity
+// to be saved with its children.
+func parseKotlinEntityCodes(table *Table, javaFields []JavaField, children []aggregateChild) entityCodes {
+	skipped := basePoFields()
+	if len(children) > 0 {
+		pk := fieldNameOf(table.Status.Name, primaryKeyColumn(table))
+		skipped = slices.DeleteFunc(slices.Clone(skipped), func(f string) bool { return f == pk })
+	}
+	javaFields = validatedJavaFields(javaFields, skipped...)
+	if len(children) > 0 {
+		javaFields = append(javaFields, parseAggregateEntityFields(children)...)
+	}
+	imports, properties := parseKotlinImportsAndProperties(javaFields, skipped...)
+	return entityCodes{Imports: sortKotlinImports(imports), Fields: properties}
+}
+
+// parseKotlinFactoryCodes returns the extension functions converting between the PO and the entity of a table,
+// the base PO members an aggregate root keeps are assigned after constructing the PO.
+func parseKotlinFactoryCodes(table *Table, javaFields []JavaField, children []aggregateChild, enums []*javaEnum, names templateNames) factoryCodes {
+	packages := layerPackages()
+	imports := []string{
+		fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+		fmt.Sprintf("%s.%s", packages.Po, names.Po),
+	}
+	inherited := basePoFields()
+	entityFields := slices.DeleteFunc(slices.Clone(javaFields), func(f JavaField) bool { return slices.Contains(inherited, f.Field) })
+	if len(children) > 0 {
+		pk := fieldNameOf(table.Status.Name, primaryKeyColumn(table))
+		for _, f := range javaFields {
+			if f.Field == pk && slices.Contains(inherited, pk) {
+				entityFields = append(entityFields, f)
+			}
+		}
+	}
+
+	codes := factoryCodes{}
+	var entityArgs, poArgs, poAssignments strings.Builder
+	for _, f := range entityFields {
+		entityArgs.WriteString(fmt.Sprintf("      %s = %s,\n", f.Field, f.Field))
+		if slices.Contains(inherited, f.Field) {
+			poAssignments.WriteString(fmt.Sprintf("\n  po.%s = %s", f.Field, f.Field))
+			continue
+		}
+		poArgs.WriteString(fmt.Sprintf("      %s = %s,\n", f.Field, f.Field))
+	}
+	codes.EntityArgs = strings.TrimSuffix(entityArgs.String(), "\n")
+	codes.PoArgs = strings.TrimSuffix(poArgs.String(), "\n")
+	codes.PoAssignments = strings.TrimPrefix(poAssignments.String(), "\n")
+
+	if len(children) > 0 {
+		params := make([]string, 0, len(children))
+		var b strings.Builder
+		for _, child := range children {
+			childNames := templateNamesOf(child.Table.Status.Name)
+			childPosName := firstLowCase(childNames.Entity) + "Pos"
+			imports = append(imports, fmt.Sprintf("%s.%s", packages.Po, childNames.Po))
+			params = append(params, fmt.Sprintf("%s: List<%s>", childPosName, childNames.Po))
+			b.WriteString(fmt.Sprintf("  entity.%ss = %s.map { it.toEntity() }\n", firstLowCase(childNames.Entity), childPosName))
+		}
+		codes.AggregateMethods = fmt.Sprintf("\nfun %s.toEntity(%s): %s {\n  val entity = toEntity()\n%s  return entity\n}\n",
+			names.Po, strings.Join(params, ", "), names.Entity, b.String())
+	}
+
+	var b strings.Builder
+	for _, e := range enums {
+		if e.Set || !e.Described() {
+			continue
+		}
+		imports = append(imports, e.PackageName())
+		b.WriteString(fmt.Sprintf("\nfun to%s(code: %s?): %s? {\n", e.ClassName, e.KotlinCodeType(), e.ClassName))
+		b.WriteString("  if (code == null) {\n    return null\n  }\n")
+		b.WriteString(fmt.Sprintf("  val value = %s.of(code)\n", e.ClassName))
+		b.WriteString(fmt.Sprintf("  if (value == null) {\n    log.warn(\"unknown %s code {}\", code)\n  }\n", e.ClassName))
+		b.WriteString("  return value\n}\n")
+	}
+	if b.Len() > 0 {
+		imports = append(imports, "org.slf4j.LoggerFactory")
+		codes.EnumMethods = fmt.Sprintf("\nprivate val log = LoggerFactory.getLogger(%s)\n%s", kotlinStringLiteral(packages.Factory+"."+names.Factory), b.String())
+	}
+	codes.Imports = sortKotlinImports(imports)
+	return codes
+}
+
+// parseKotlinRepositoryCodes returns the imports, the child mapper constructor properties and the methods of a
+// repository querying through QueryWrappers of column names.
+func parseKotlinRepositoryCodes(table *Table, javaFields []JavaField, children []aggregateChild, names templateNames) repositoryCodes {
+	packages := layerPackages()
+	fields := make(map[string]JavaField)
+	for _, f := range javaFields {
+		fields[f.Field] = f
+	}
+	imports := []string{
+		fmt.Sprintf("%s.%s", packages.Mapper, names.Mapper),
+		"org.springframework.stereotype.Repository",
+	}
+
+	var b strings.Builder
+	methods := repositoryMethodsOf(table, javaFields)
+	if len(methods) > 0 {
+		imports = append(imports,
+			"com.baomidou.mybatisplus.core.conditions.query.QueryWrapper",
+			fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+			fmt.Sprintf("%s.%s", packages.Po, names.Po),
+			packages.Factory+".toEntity",
+		)
+	}
+	for _, m := range methods {
+		params, conditions := make([]string, 0, len(m.columns)), make([]string, 0, len(m.columns))
+		for _, column := range m.columns {
+			f := fields[fieldNameOf(table.Status.Name, column)]
+			imports = append(append(imports, f.PackageName), f.Imports...)
+			if m.kind == "listIn" {
+				params = append(params, fmt.Sprintf("%ss: Collection<%s>", f.Field, kotlinType(f.JavaType)))
+				conditions = append(conditions, fmt.Sprintf("        .`in`(%s, %ss)", kotlinStringLiteral(column), f.Field))
+				continue
+			}
+			params = append(params, fmt.Sprintf("%s: %s", f.Field, kotlinType(f.JavaType)))
+			conditions = append(conditions, fmt.Sprintf("        .eq(%s, %s)", kotlinStringLiteral(column), f.Field))
+		}
+		returnType := fmt.Sprintf("List<%s>", names.Entity)
+		switch m.kind {
+		case "find":
+			returnType = names.Entity + "?"
+		case "exists":
+			returnType = "Boolean"
+		}
+		b.WriteString(fmt.Sprintf("\n  fun %s(%s): %s {\n", m.name, strings.Join(params, ", "), returnType))
+		if m.kind == "listIn" {
+			b.WriteString(fmt.Sprintf("    if (%ss.isEmpty()) {\n      return emptyList()\n    }\n", fieldNameOf(table.Status.Name, m.columns[0])))
+		}
+		b.WriteString(fmt.Sprintf("    val wrapper = QueryWrapper<%s>()\n%s\n", names.Po, strings.Join(conditions, "\n")))
+		switch m.kind {
+		case "find":
+			b.WriteString(fmt.Sprintf("    return %s.selectOne(wrapper)?.toEntity()\n", names.MapperField))
+		case "exists":
+			b.WriteString(fmt.Sprintf("    return %s.selectCount(wrapper) > 0\n", names.MapperField))
+		default:
+			b.WriteString(fmt.Sprintf("    return %s.selectList(wrapper).map { it.toEntity() }\n", names.MapperField))
+		}
+		b.WriteString("  }\n")
+	}
+
+	aggregateImports, fieldCodes, aggregateCodes := parseKotlinAggregateRepositoryCodes(table, javaFields, children, names)
+	imports = append(imports, aggregateImports...)
+	b.WriteString(aggregateCodes)
+	return repositoryCodes{Imports: sortKotlinImports(imports), Fields: fieldCodes, Methods: b.String()}
+}
+
+// parseKotlinAggregateRepositoryCodes returns the imports, child mapper constructor properties and the methods
+// loading and saving an aggregate root with its children.
+func parseKotlinAggregateRepositoryCodes(table *Table, javaFields []JavaField, children []aggregateChild, names templateNames) (imports []string, fieldCodes, methodCodes string) {
+	pk := primaryKeyColumn(table)
+	if pk == "" || len(children) == 0 {
+		return nil, "", ""
+	}
+	pkField := fieldNameOf(table.Status.Name, pk)
+	pkType, pkNullable := "Long", true
+	for _, f := range javaFields {
+		if f.Field == pkField {
+			pkType = kotlinType(f.JavaType)
+			// a key inherited from the base PO is a platform type
+			pkNullable = f.Nullable || slices.Contains(basePoFields(), pkField)
+		}
+	}
+	packages := layerPackages()
+	imports = []string{
+		"com.baomidou.mybatisplus.core.conditions.query.QueryWrapper",
+		fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+		packages.Factory + ".toEntity",
+		packages.Factory + ".toPo",
+		"org.springframework.transaction.annotation.Transactional",
+	}
+
+	var fields, load, save strings.Builder
+	loadArgs := make([]string, 0, len(children))
+	for _, child := range children {
+		childNames := templateNamesOf(child.Table.Status.Name)
+		childField := fieldNameOf(child.Table.Status.Name, child.Column)
+		rootKey := "po." + fieldNameOf(table.Status.Name, child.RootColumn)
+		imports = append(imports,
+			fmt.Sprintf("%s.%s", packages.Mapper, childNames.Mapper),
+			fmt.Sprintf("%s.%s", packages.Po, childNames.Po),
+		)
+
+		fields.WriteString(fmt.Sprintf("    private val %s: %s,\n", childNames.MapperField, childNames.Mapper))
+
+		childPosName := firstLowCase(childNames.Entity) + "Pos"
+		loadArgs = append(loadArgs, childPosName)
+		load.WriteString(fmt.Sprintf("    val %s = %s.selectList(QueryWrapper<%s>()\n        .eq(%s, %s))\n",
+			childPosName, childNames.MapperField, childNames.Po, kotlinStringLiteral(child.Column), rootKey))
+
+		save.WriteString(fmt.Sprintf("    %s.delete(QueryWrapper<%s>()\n        .eq(%s, %s))\n",
+			childNames.MapperField, childNames.Po, kotlinStringLiteral(child.Column), rootKey))
+		save.WriteString(fmt.Sprintf("    for (childPo in aggregate.%ss.map { it.toPo() }) {\n", firstLowCase(childNames.Entity)))
+		save.WriteString(fmt.Sprintf("      childPo.%s = %s%s\n", childField, rootKey, kotlinNonNull(pkNullable && !childFieldNullable(child))))
+		save.WriteString(fmt.Sprintf("      %s.insert(childPo)\n    }\n", childNames.MapperField))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  fun findAggregateBy%s(%s: %s): %s? {\n", firstUpCase(pkField), pkField, pkType, names.Entity))
+	b.WriteString(fmt.Sprintf("    val po = %s.selectById(%s) ?: return null\n", names.MapperField, pkField))
+	b.WriteString(load.String())
+	b.WriteString(fmt.Sprintf("    return po.toEntity(%s)\n  }\n", strings.Join(loadArgs, ", ")))
+
+	b.WriteString("\n  @Transactional(rollbackFor = [Exception::class])\n")
+	b.WriteString(fmt.Sprintf("  fun saveAggregate(aggregate: %s): %s {\n", names.Entity, pkType))
+	b.WriteString("    val po = aggregate.toPo()\n")
+	b.WriteString(fmt.Sprintf("    if (po.%s == null) {\n      %s.insert(po)\n    } else {\n      %s.updateById(po)\n    }\n", pkField, names.MapperField, names.MapperField))
+	b.WriteString(save.String())
+	b.WriteString(fmt.Sprintf("    return po.%s%s\n  }\n", pkField, kotlinNonNull(pkNullable)))
+	return imports, fields.String(), b.String()
+}
+
+// childFieldNullable reports whether the column of a child referencing its aggregate root is nullable.
+func childFieldNullable(child aggregateChild) bool {
+	for _, col := range child.Table.Columns {
+		if col.Field == child.Column {
+			return strings.EqualFold(col.Null, "YES")
+		}
+	}
+	return true
+}
+
+// kotlinNonNull returns the not-null assertion of a nullable value assigned to a non-null one.
+func kotlinNonNull(nullable bool) string {
+	if nullable {
+		return "!!"
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// builtinLayer names the built-in templates generating a layer
+type builtinLayer struct {
+	name      string
+	templates []string
+}
+
+// builtinLayers registers the built-in generators in generation order
+var builtinLayers = []builtinLayer{
+	{name: "enum", templates: []string{"enum.java.tmpl", "enum-set-type-handler.java.tmpl", "enum.kt.tmpl", "enum-set-type-handler.kt.tmpl"}},
+	{name: "po", templates: []string{"po.java.tmpl", "po.kt.tmpl"}},
+	{name: "mapper", templates: []string{"mapper.java.tmpl", "mapper.xml.tmpl", "mapper.kt.tmpl"}},
+	{name: "repository", templates: []string{"repository.java.tmpl", "repository-gap.java.tmpl", "repository.kt.tmpl"}},
+	{name: "factory", templates: []string{"factory.java.tmpl", "factory.kt.tmpl"}},
+	{name: "entity", templates: []string{"entity.java.tmpl", "entity-gap.java.tmpl", "entity.kt.tmpl"}},
+	{name: "service", templates: []string{"app-service.java.tmpl", "app-service-gap.java.tmpl", "app-service.kt.tmpl"}},
+	{name: "assembler", templates: []string{"assembler.java.tmpl", "assembler.kt.tmpl"}},
+	{name: "command", templates: []string{"command.java.tmpl", "command.kt.tmpl"}},
+}
+
+// builtinTemplateOrder returns the generation order of the built-in templates.
+func builtinTemplateOrder() []string {
+	names := make([]string, 0)
+	for _, l := range builtinLayers {
+		names = append(names, l.templates...)
+	}
+	return names
+}
+
+// layerRegistry holds the loaded templates by the name of the layer they generate,
+// a template other than the built-in ones is a layer named after its file, e.g. dto.java.tmpl -> dto
+type layerRegistry struct {
+	names     []string // in generation order
+	templates map[string][]*artifactTemplate
+}
+
+// newLayerRegistry groups templates into layers.
+func newLayerRegistry(templates []*artifactTemplate) *layerRegistry {
+	layerOf := make(map[string]string)
+	for _, l := range builtinLayers {
+		for _, name := range l.templates {
+			layerOf[name] = l.name
+		}
+	}
+	r := &layerRegistry{templates: make(map[string][]*artifactTemplate)}
+	for _, t := range templates {
+		name, ok := layerOf[t.name]
+		if !ok {
+			name, _, _ = strings.Cut(t.name, ".")
+		}
+		if _, ok = r.templates[name]; !ok {
+			r.names = append(r.names, name)
+		}
+		r.templates[name] = append(r.templates[name], t)
+	}
+	return r
+}
+
+// has reports whether a layer is registered.
+func (r *layerRegistry) has(name string) bool {
+	_, ok := r.templates[name]
+	return ok
+}
+
+// selectTemplates returns the templates of the layers in only, every layer if only is empty, less the layers in skip.
+func (r *layerRegistry) selectTemplates(only, skip []string) ([]*artifactTemplate, error) {
+	for _, name := range append(slices.Clone(only), skip...) {
+		if !r.has(name) {
+			return nil, fmt.Errorf("unknown layer %s, expected one of %s", name, strings.Join(r.names, ", "))
+		}
+	}
+	templates := make([]*artifactTemplate, 0)
+	for _, name := range r.names {
+		if (len(only) == 0 || slices.Contains(only, name)) && !slices.Contains(skip, name) {
+			templates = append(templates, r.templates[name]...)
+		}
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no layer left to generate")
+	}
+	return templates, nil
+}
+
+// parseLayerNames splits a comma separated list of layer names.
+func parseLayerNames(s string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
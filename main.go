@@ -28,315 +28,262 @@ package main
 import (
 	"flag"
 	"fmt"
-	"gorm.io/gorm"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
-	"time"
 )
 
-const genOutputDir = "gen-output"
-
 var (
-	err        error
-	mDB        *gorm.DB
-	host       string
-	port       int
-	username   string
-	password   string
-	schemaName string
-	tableName  string
-	domainName string
+	err            error
+	dialect        string
+	host           string
+	port           int
+	username       string
+	password       string
+	schemaName     string
+	pgSchemaName   string
+	tableName      string
+	excludePattern string
+	allTables      bool
+	domainName     string
+	ddlFile        string
+	snapshotFile   string
+	snapshotOutput string
+	fkPattern      string
+	typeMapFile    string
+	templateDir    string
+	layoutFile     string
+	configFile     string
+	layerNames     string
+	skipNames      string
+	generationGap  bool
+	outputDir      string
+	projectMode    bool
+	basePackage    string
+	javaTypeMapper *typeMapper
+	templates      []*artifactTemplate
 )
 
 var buildVersion string
 
 func main() {
+	// the first argument is an optional command
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [inspect|check] [flags]\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(flag.CommandLine.Output(), "  (none)    生成代码\n  inspect   导出表结构快照\n  check     检查已生成的代码是否与表结构一致，不一致时以非零状态码退出\n\n")
+		flag.PrintDefaults()
+	}
+	flag.StringVar(&dialect, "dialect", dialectMySQL, "数据库类型，mysql、postgres 或 sqlite")
 	flag.StringVar(&username, "u", "root", "用户名")
 	flag.StringVar(&password, "p", "root", "密码")
 	flag.StringVar(&host, "h", "localhost", "主机名，默认 localhost")
-	flag.IntVar(&port, "P", 3306, "端口号，默认 3306")
-	flag.StringVar(&schemaName, "d", "db_local", "数据库名")
-	flag.StringVar(&tableName, "t", "tb_user", "表名")
+	flag.IntVar(&port, "P", 3306, "端口号，默认 3306，postgres 默认 5432")
+	flag.StringVar(&schemaName, "d", "db_local", "数据库名，sqlite 为数据库文件路径")
+	flag.StringVar(&pgSchemaName, "schema", "public", "PostgreSQL 模式名")
+	flag.StringVar(&tableName, "t", "tb_user", "表名，多个用逗号分隔，支持 glob（tb_order*）和 /正则/")
+	flag.StringVar(&excludePattern, "exclude", "", "排除的表名，格式同 -t")
+	flag.BoolVar(&allTables, "all", false, "生成数据库中的所有表，忽略 -t")
 	flag.StringVar(&domainName, "D", "user", "领域名")
-	flag.Parse()
-
-	// fetch table info
-	connectToDB()
-	tableStatus, columns := readTablePrototype()
-
-	// parse class members
-	javaFields := parseJavaFields(columns)
-
-	genPO(tableStatus, javaFields)
-	genMapper(tableStatus)
-	genRepository(tableStatus)
-	genFactory(tableStatus)
-	genEntity(tableStatus, javaFields)
-	genAppService(tableStatus)
-	genAssembler(tableStatus)
-}
-
-func genJavadoc(className string, tableStatus *TableStatus) string {
-	content := `/**
- * {{className}} - {{tableComment}}
- *
- * <p>table_name: {{tableName}}</p>
- *
- * @author ddd-gen-mysql
- * @date {{timeString}}
- */`
-	content = strings.ReplaceAll(content, "{{className}}", className)
-	content = strings.ReplaceAll(content, "{{tableComment}}", tableStatus.Comment)
-	content = strings.ReplaceAll(content, "{{tableName}}", tableStatus.Name)
-	content = strings.ReplaceAll(content, "{{timeString}}", time.Now().String())
-	return content
-}
-
-func genPO(tableStatus *TableStatus, javaFields []JavaField) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	className := fmt.Sprintf("%sPo", firstUpCase(camelCase(entityName)))
-	importCodes, fieldCodes := parseJavaImportsAndFields(javaFields)
-
-	codes := `package com.mahuafm.phoenix.{{domainName}}.infrastructure.persistence.po;
-
-import com.baomidou.mybatisplus.annotation.TableName;
-import com.mahuafm.phoenix.util.infrastructure.persistence.po.base.BaseAutoIdPo;
-{{importCodes}}
-import lombok.Data;
-import lombok.EqualsAndHashCode;
-
-{{javadoc}}
-@Data
-@EqualsAndHashCode(callSuper = true)
-@TableName("{{tableName}}")
-public class {{className}} extends BaseAutoIdPo {
-
-{{fieldCodes}}
-
-}
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{importCodes}}", importCodes)
-	codes = strings.ReplaceAll(codes, "{{tableName}}", tableStatus.Name)
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
-	codes = strings.ReplaceAll(codes, "{{fieldCodes}}", fieldCodes)
-
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "infrastructure", "persistence", "po"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
+	flag.StringVar(&ddlFile, "ddl", "", "CREATE TABLE 语句的 SQL 文件，指定后不再连接数据库")
+	flag.StringVar(&fkPattern, "fk-pattern", "", "没有外键约束时按列名推断关联，如 {table}_id")
+	flag.StringVar(&typeMapFile, "type-map", "", "SQL 类型到 Java 类型的映射文件，.json 或 .yaml")
+	flag.StringVar(&basePackage, "package", "", "领域的根包名，{domain} 替换为领域名，如 com.example.{domain}")
+	flag.StringVar(&layoutFile, "layout", "", "项目结构文件，配置根包名、各层包名和工具类，.json 或 .yaml")
+	flag.StringVar(&target, "target", targetMybatisPlus, "持久层框架，mybatis-plus、mybatis、jpa、jdbc 或 r2dbc")
+	flag.StringVar(&lang, "lang", langJava, "生成代码的语言，java 或 kotlin，kotlin 只支持 mybatis-plus")
+	flag.StringVar(&validation, "validation", "", "实体字段的 Bean Validation 注解，javax 或 jakarta，默认不生成")
+	flag.StringVar(&templateDir, "templates", "", "模板目录，同名 .tmpl 文件覆盖内置模板，新文件生成新的产物")
+	flag.StringVar(&layerNames, "layers", "", "只生成的层，逗号分隔：enum、po、mapper、repository、factory、entity、service、assembler、command 或自定义模板名")
+	flag.StringVar(&skipNames, "skip", "", "不生成的层，格式同 -layers")
+	flag.BoolVar(&generationGap, "generation-gap", false, "实体、仓储和应用服务生成每次覆盖的抽象基类，以及只在缺失时创建的子类")
+	flag.StringVar(&outputDir, "out", "gen-output", "输出目录，指定 -project 时为项目目录，默认为当前目录")
+	flag.BoolVar(&projectMode, "project", false, "按 pom.xml 或 build.gradle 识别项目和模块，直接写入各模块的 src/main/java")
+	flag.BoolVar(&dryRun, "dry-run", false, "只输出会新建、修改和不变的文件，不写入")
+	flag.BoolVar(&showDiff, "diff", false, "输出已有文件与新生成文件的 unified diff，不写入")
+	flag.StringVar(&conflictMode, "conflict", conflictNew, "已存在且没有生成标记的手写文件：new 生成到同目录的 .new 文件，refuse 报错")
+	flag.StringVar(&configFile, "config", defaultConfigFile, "项目配置文件，命令行参数优先于配置文件，默认文件不存在时忽略")
+	flag.StringVar(&snapshotFile, "from-snapshot", "", "inspect 导出的快照文件，指定后不再连接数据库")
+	flag.StringVar(&snapshotOutput, "o", "schema-snapshot.yaml", "inspect 输出的快照文件，.json 或 .yaml")
+	_ = flag.CommandLine.Parse(args)
+	if command != "" && command != "inspect" && command != "check" {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, err = os.Stat(configFile); err == nil || isFlagPassed("config") {
+		c, err := readConfig(configFile)
+		exitOnUsageError(err)
+		c.apply()
+	}
+	if conflictMode != conflictNew && conflictMode != conflictRefuse {
+		exitOnUsageError(fmt.Errorf("unknown -conflict %s, expected new or refuse", conflictMode))
+	}
+	exitOnUsageError(validateTarget(target))
+	exitOnUsageError(validateLang(lang))
+	exitOnUsageError(validateLangOptions())
+	exitOnUsageError(validateValidation(validation))
+	if projectMode {
+		if !isFlagPassed("out") {
+			outputDir = "."
+		}
+		project, err = detectJavaProject(outputDir)
+		exitOnUsageError(err)
+	}
+	if dialect == dialectPostgres && !isFlagPassed("P") {
+		port = 5432
+	}
+
+	include := tableName
+	if allTables {
+		include = ""
+	}
+	filter, err := newTableFilter(include, excludePattern)
+	exitOnUsageError(err)
+	if layoutFile != "" {
+		layout, err = readProjectLayout(layoutFile)
+		exitOnUsageError(err)
+	}
+	if basePackage != "" {
+		layout.BasePackage = basePackage
+		exitOnUsageError(layout.validate())
+	}
+	templates, err = loadTemplates(templateDir)
+	exitOnUsageError(err)
+	registry := newLayerRegistry(templates)
+	exitOnUsageError(config.validateLayers(registry))
+	templates, err = registry.selectTemplates(parseLayerNames(layerNames), parseLayerNames(skipNames))
+	exitOnUsageError(err)
+	if typeMapFile != "" {
+		typeMapping, err = readTypeMapping(typeMapFile)
+		exitOnUsageError(err)
+	}
+
+	// fetch table names
+	introspector, err := newIntrospector()
+	if err != nil {
+		panic(err)
+	}
+	names, err := introspector.ListTables()
+	if err != nil {
+		panic(err)
+	}
+	tables, missing := filter.filter(names)
+	// a snapshot decides the dialect once loaded
+	javaTypeMapper, err = newTypeMapper(dialect, typeMapping)
+	exitOnUsageError(err)
+
+	if command == "inspect" {
+		if len(missing) > 0 {
+			exitOnUsageError(fmt.Errorf("table not found: %s", strings.Join(missing, ", ")))
+		}
+		if err = inspect(introspector, tables); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	checkOnly = command == "check"
+	failures := make(map[string]error)
+	for _, name := range missing {
+		failures[name] = fmt.Errorf("table not found")
+	}
+	selected := make(map[string]bool, len(tables))
+	for _, name := range tables {
+		selected[name] = true
+	}
+	// the tables a selected one may compose as an aggregate root are read as well, even when left out by -t or
+	// -exclude, every table is when -fk-pattern infers the foreign keys between them
+	related := aggregateCandidates(names, tables)
+	if fkPattern != "" {
+		related = slices.DeleteFunc(slices.Clone(names), func(name string) bool { return selected[name] })
+	}
+	loaded := make([]*Table, 0, len(tables)+len(related))
+	for _, name := range append(slices.Clone(tables), related...) {
+		table, err := readTable(introspector, name)
+		if err != nil {
+			failures[name] = err
+			fmt.Printf("%s: %v\n", name, err)
+			continue
+		}
+		excludeColumns(table)
+		loaded = append(loaded, table)
+	}
+	inferForeignKeys(loaded, fkPattern)
+	aggregates := resolveAggregates(loaded)
+
+	generated := 0
+	for _, table := range loaded {
+		if !selected[table.Status.Name] {
+			continue
+		}
+		if !checkOnly {
+			fmt.Printf("[%s]\n", table.Status.Name)
+		}
+		if err := generate(table, aggregates[table.Status.Name]); err != nil {
+			failures[table.Status.Name] = err
+			fmt.Printf("%s: %v\n", table.Status.Name, err)
+		} else {
+			generated++
+		}
+		if !checkOnly {
+			fmt.Println()
+		}
+	}
+
+	if checkOnly {
+		fmt.Printf("%d table(s) checked, %d failed, %d stale file(s)\n", generated, len(failures), len(staleFiles))
+		if len(staleFiles) > 0 {
+			fmt.Println("regenerate the code, the schema no longer matches it")
+		}
+	} else {
+		fmt.Printf("%d table(s) generated, %d failed\n", generated, len(failures))
+	}
+	if dryRun || showDiff {
+		fmt.Printf("dry run: %s\n", fileCountSummary())
+	}
+	for _, name := range sortedKeys(failures) {
+		fmt.Printf("  %s: %v\n", name, failures[name])
+	}
+	if len(failures) > 0 || len(staleFiles) > 0 {
+		os.Exit(1)
+	}
 }
 
-func genMapper(tableStatus *TableStatus) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	className := fmt.Sprintf("%sMapper", firstUpCase(camelCase(entityName)))
-	poClassName := fmt.Sprintf("%sPo", firstUpCase(camelCase(entityName)))
-
-	codes := `package com.mahuafm.phoenix.{{domainName}}.infrastructure.persistence.mapper;
-
-import com.baomidou.mybatisplus.core.mapper.BaseMapper;
-import com.mahuafm.phoenix.{{domainName}}.infrastructure.persistence.po.{{poClassName}};
-
-{{javadoc}}
-public interface {{className}} extends BaseMapper<{{poClassName}}> {}
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{poClassName}}", poClassName)
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
-
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "infrastructure", "persistence", "mapper"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
-}
-
-func genRepository(tableStatus *TableStatus) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	className := fmt.Sprintf("%sRepository", firstUpCase(camelCase(entityName)))
-	mapperClassName := fmt.Sprintf("%sMapper", firstUpCase(camelCase(entityName)))
-	mapperFieldName := fmt.Sprintf("%sMapper", camelCase(entityName))
-
-	codes := `package com.mahuafm.phoenix.{{domainName}}.infrastructure.repository;
-
-import com.mahuafm.phoenix.{{domainName}}.infrastructure.persistence.mapper.{{mapperClassName}};
-import javax.annotation.Resource;
-import lombok.extern.slf4j.Slf4j;
-import org.springframework.stereotype.Repository;
-
-{{javadoc}}
-@Repository
-@Slf4j
-public class {{className}} {
-
-  @Resource
-  private {{mapperClassName}} {{mapperFieldName}};
-
-}
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{mapperClassName}}", mapperClassName)
-	codes = strings.ReplaceAll(codes, "{{mapperFieldName}}", mapperFieldName)
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
-
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "infrastructure", "repository"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
-}
-
-func genFactory(tableStatus *TableStatus) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	entityClassName := firstUpCase(camelCase(entityName))
-	className := fmt.Sprintf("%sFactory", entityClassName)
-	poClassName := fmt.Sprintf("%sPo", entityClassName)
-
-	codes := `package com.mahuafm.phoenix.{{domainName}}.infrastructure.factory;
-
-import com.mahuafm.phoenix.{{domainName}}.domain.{{domainName}}.entity.{{entityClassName}};
-import com.mahuafm.phoenix.{{domainName}}.infrastructure.persistence.po.{{poClassName}};
-import com.mahuafm.phoenix.util.bean.BeanCopyUtil;
-import java.util.Collections;
-import java.util.List;
-import java.util.Objects;
-import java.util.stream.Collectors;
-import lombok.extern.slf4j.Slf4j;
-import org.springframework.util.CollectionUtils;
-
-{{javadoc}}
-@Slf4j
-public class {{className}} {
-
-  public static {{entityClassName}} fromPo({{poClassName}} po) {
-    if (po == null) {
-      return null;
-    }
-    var entity = BeanCopyUtil.copy(po, {{entityClassName}}.class);
-    // TODO extra code to invoke setter
-    return entity;
-  }
-
-  public static List<{{entityClassName}}> fromPos(List<{{poClassName}}> pos) {
-    if (CollectionUtils.isEmpty(pos)) {
-      return Collections.emptyList();
-    }
-    return pos.stream()
-        .map({{className}}::fromPo)
-        .filter(Objects::nonNull)
-        .collect(Collectors.toList());
-  }
-
-  public static {{poClassName}} toPo({{entityClassName}} entity) {
-    var po = BeanCopyUtil.copy(entity, {{poClassName}}.class);
-    // TODO extra code to invoke setter
-    return po;
-  }
-
-  public static List<{{poClassName}}> toPos(List<{{entityClassName}}> entities) {
-    if (CollectionUtils.isEmpty(entities)) {
-      return Collections.emptyList();
-    }
-    return entities.stream()
-        .map({{className}}::toPo)
-        .filter(Objects::nonNull)
-        .collect(Collectors.toList());
-  }
-
-}
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{entityClassName}}", entityClassName)
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
-	codes = strings.ReplaceAll(codes, "{{poClassName}}", poClassName)
-
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "infrastructure", "factory"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
-}
-
-func genEntity(tableStatus *TableStatus, javaFields []JavaField) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	className := firstUpCase(camelCase(entityName))
-	importCodes, fieldCodes := parseJavaImportsAndFields(javaFields)
-
-	codes := `package com.mahuafm.phoenix.{{domainName}}.domain.{{domainName}}.entity;
-
-{{importCodes}}
-import lombok.Data;
-
-{{javadoc}}
-@Data
-public class {{className}} {
-
-{{fieldCodes}}
-
-}
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
-	codes = strings.ReplaceAll(codes, "{{importCodes}}", importCodes)
-	codes = strings.ReplaceAll(codes, "{{fieldCodes}}", fieldCodes)
-
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "domain", domainName, "entity"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
-}
-
-func genAppService(tableStatus *TableStatus) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	entityClassName := firstUpCase(camelCase(entityName))
-	className := fmt.Sprintf("%sAppService", entityClassName)
-	repositoryClassName := fmt.Sprintf("%sRepository", entityClassName)
-	repositoryFieldName := fmt.Sprintf("%sRepository", camelCase(entityName))
-
-	codes := `package com.mahuafm.phoenix.{{domainName}}.application.service;
-
-import com.mahuafm.phoenix.{{domainName}}.infrastructure.repository.{{repositoryClassName}};
-import javax.annotation.Resource;
-import org.springframework.stereotype.Service;
-
-{{javadoc}}
-@Service
-public class {{className}} {
-
-  @Resource
-  private {{repositoryClassName}} {{repositoryFieldName}};
-
-}
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
-	codes = strings.ReplaceAll(codes, "{{repositoryClassName}}", repositoryClassName)
-	codes = strings.ReplaceAll(codes, "{{repositoryFieldName}}", repositoryFieldName)
-
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "application", "service"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
-}
-
-func genAssembler(tableStatus *TableStatus) {
-	entityName := tryRemoveTablePrefix(tableStatus.Name)
-	className := fmt.Sprintf("%sAssembler", firstUpCase(camelCase(entityName)))
-	codes := `package com.mahuafm.phoenix.{{domainName}}.application.assembler;
-
-{{javadoc}}
-public class {{className}} {
+// exitOnUsageError reports an invalid flag, configuration or input file and exits with status 2 like flag does,
+// a nil error is ignored.
+func exitOnUsageError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 }
-`
-	codes = strings.ReplaceAll(codes, "{{domainName}}", domainName)
-	codes = strings.ReplaceAll(codes, "{{javadoc}}", genJavadoc(className, tableStatus))
-	codes = strings.ReplaceAll(codes, "{{className}}", className)
 
-	path := fmt.Sprintf("%s", filepath.Join(genOutputDir, domainName, "application", "assembler"))
-	filename := fmt.Sprintf("./%s/%s.java", path, className)
-	writeFile(path, filename, codes)
-	fmt.Printf("%s: %s\n", className, filename)
+// generate renders every template against a table, children are the tables composed into it as an aggregate root.
+// A table configured into another domain is generated there, a table whose key conflicts with the base PO has a PO
+// declaring every column.
+func generate(table *Table, children []aggregateChild) error {
+	if domain := tableConfig(table.Status.Name).Domain; domain != "" {
+		defer func(d string) { domainName = d }(domainName)
+		domainName = domain
+	}
+	if !springDataTarget() && conflictsWithBasePo(table) {
+		defer func(basePo string) { layout.Utils.BasePo = basePo }(layout.Utils.BasePo)
+		layout.Utils.BasePo = ""
+	}
+	files, err := render(templates, newTemplateData(table, children))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err = emitFile(f); err != nil {
+			return err
+		}
+	}
+	return nil
 }
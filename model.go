@@ -1,29 +1,69 @@
 package main
 
+// Table defines everything we introspected from a mysql table
+type Table struct {
+	Status      TableStatus           `json:"status" yaml:"status"`
+	Columns     []ColumnsStatement    `json:"columns" yaml:"columns"`
+	Indexes     []IndexStatement      `json:"indexes,omitempty" yaml:"indexes,omitempty"`
+	ForeignKeys []ForeignKeyStatement `json:"foreignKeys,omitempty" yaml:"foreignKeys,omitempty"`
+}
+
 // TableStatus defines mysql table status we needed
 type TableStatus struct {
-	Name      string
-	Rows      uint64
-	Collation string
-	Comment   string
+	Name      string `json:"name" yaml:"name"`
+	Rows      uint64 `json:"-" yaml:"-"` // an estimate, left out of snapshots so that they only change with the schema
+	Collation string `json:"collation,omitempty" yaml:"collation,omitempty"`
+	Comment   string `json:"comment,omitempty" yaml:"comment,omitempty"`
 }
 
 // ColumnsStatement defines mysql columns statement we needed
 type ColumnsStatement struct {
-	Field   string
-	Type    string
-	Null    string
-	Key     string
-	Comment string
-	Default string
-	Extra   string
+	Field                string `json:"field" yaml:"field"`
+	Type                 string `json:"type" yaml:"type"` // full column type, e.g. bigint(20) unsigned
+	Null                 string `json:"null" yaml:"null"`
+	Key                  string `json:"key,omitempty" yaml:"key,omitempty"`
+	Comment              string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Default              string `json:"default,omitempty" yaml:"default,omitempty"`
+	HasDefault           bool   `json:"hasDefault,omitempty" yaml:"hasDefault,omitempty"` // a default other than NULL, which may be ''
+	Extra                string `json:"extra,omitempty" yaml:"extra,omitempty"`
+	DataType             string `json:"dataType" yaml:"dataType"` // bare data type, e.g. bigint
+	CharMaxLength        int64  `json:"charMaxLength,omitempty" yaml:"charMaxLength,omitempty"`
+	NumericPrecision     int64  `json:"numericPrecision,omitempty" yaml:"numericPrecision,omitempty"`
+	NumericScale         int64  `json:"numericScale,omitempty" yaml:"numericScale,omitempty"`
+	Unsigned             bool   `json:"unsigned,omitempty" yaml:"unsigned,omitempty"`
+	GenerationExpression string `json:"generationExpression,omitempty" yaml:"generationExpression,omitempty"`
+	OrdinalPosition      int    `json:"ordinalPosition" yaml:"ordinalPosition"`
+	Collation            string `json:"collation,omitempty" yaml:"collation,omitempty"`
+}
+
+// IndexStatement defines a mysql index, the PRIMARY index included
+type IndexStatement struct {
+	Name    string   `json:"name" yaml:"name"`
+	Primary bool     `json:"primary,omitempty" yaml:"primary,omitempty"`
+	Unique  bool     `json:"unique,omitempty" yaml:"unique,omitempty"`
+	Columns []string `json:"columns" yaml:"columns,flow"`
+}
+
+// ForeignKeyStatement defines a mysql foreign key constraint
+type ForeignKeyStatement struct {
+	Name              string   `json:"name" yaml:"name"`
+	Columns           []string `json:"columns" yaml:"columns,flow"`
+	ReferencedTable   string   `json:"referencedTable" yaml:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns" yaml:"referencedColumns,flow"`
 }
 
 // JavaField defines POJO members
 type JavaField struct {
 	JavaType    string
 	Field       string
+	Column      string // column the member maps, empty for the members of no column
 	Comment     string
 	PackageName string
+	Imports     []string // further imports of JavaType and Annotations, e.g. java.util.EnumSet
+	Annotations []string // annotations placed above the member
 	IsPri       bool
+	Nullable    bool // the column accepts NULL or is filled in by the database, e.g. auto increment
+	// Bean Validation constraints of the column and their imports, see -validation
+	Constraints       []string
+	ConstraintImports []string
 }
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// mapperColumn maps a column to its PO member in the XML mapper of the mybatis target
+type mapperColumn struct {
+	Column      string
+	Property    string
+	TypeHandler string // fully qualified type handler of an enum column, empty for the default one
+	Key         bool   // part of the primary key
+	Generated   bool   // auto increment
+	HasDefault  bool   // the database fills in the column an insert leaves out
+}
+
+// Param returns the #{} parameter of the column, the property of the parameter named prefix if any, e.g. po.
+func (c mapperColumn) Param(prefix string) string {
+	property := c.Property
+	if prefix != "" {
+		property = prefix + "." + property
+	}
+	if c.TypeHandler != "" {
+		return fmt.Sprintf("#{%s,typeHandler=%s}", property, c.TypeHandler)
+	}
+	return fmt.Sprintf("#{%s}", property)
+}
+
+// BatchParam returns the value of the column in a row of insertBatch, the DEFAULT of the database for a null member
+// of a column with a default. SQLite knows no DEFAULT in VALUES, the member is bound as is.
+func (c mapperColumn) BatchParam(prefix string) string {
+	if !c.HasDefault || dialect == dialectSqlite {
+		return c.Param(prefix)
+	}
+	return fmt.Sprintf(`<choose><when test="%s.%s != null">%s</when><otherwise>DEFAULT</otherwise></choose>`, prefix, c.Property, c.Param(prefix))
+}
+
+// Quoted returns the quoted column name the statements use.
+func (c mapperColumn) Quoted() string {
+	return quoteIdentifier(c.Column)
+}
+
+// quoteIdentifier quotes a table or column name for the SQL of the dialect so that reserved words like order or key
+// stay identifiers, with backticks for MySQL and double quotes for PostgreSQL and SQLite.
+func quoteIdentifier(name string) string {
+	quote := "`"
+	if dialect == dialectPostgres || dialect == dialectSqlite {
+		quote = `"`
+	}
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// parseMybatisMapperCodes returns the columns of the XML mapper of a table and the query methods and statements
+// besides insert, insertBatch, updateByIdSelective, selectById and deleteById: those the repository calls, and a
+// select and a delete by every single column foreign key saving aggregate children.
+func parseMybatisMapperCodes(table *Table, javaFields []JavaField, enums []*javaEnum, names templateNames) mapperCodes {
+	codes := mapperCodes{Table: quoteIdentifier(table.Status.Name)}
+	columns := make(map[string]mapperColumn)
+	fields := make(map[string]JavaField)
+	keyParams := make([]string, 0)
+	imports := []string{
+		"java.util.List",
+		"org.apache.ibatis.annotations.Mapper",
+		"org.apache.ibatis.annotations.Param",
+		fmt.Sprintf("%s.%s", layerPackages().Po, names.Po),
+	}
+	for i, col := range table.Columns {
+		f := javaFields[i]
+		c := mapperColumn{Column: col.Field, Property: f.Field, Key: f.IsPri, Generated: strings.Contains(strings.ToLower(col.Extra), "auto_increment"), HasDefault: col.HasDefault}
+		for _, e := range enums {
+			if e.Column.Field == col.Field {
+				c.TypeHandler = layerPackages().Handler + "." + e.TypeHandlerClassName()
+			}
+		}
+		codes.Columns = append(codes.Columns, c)
+		if !generatedColumn(col) {
+			codes.InsertColumns = append(codes.InsertColumns, c)
+			if !c.Key {
+				codes.UpdateColumns = append(codes.UpdateColumns, c)
+			}
+		}
+		if c.Key {
+			codes.KeyColumns = append(codes.KeyColumns, c)
+			keyParams = append(keyParams, fmt.Sprintf("@Param(%s) %s %s", javaStringLiteral(f.Field), f.JavaType, f.Field))
+			imports = append(append(imports, f.PackageName), f.Imports...)
+		}
+		if c.Key && c.Generated {
+			codes.GeneratedKey = f.Field
+		}
+		columns[f.Field] = c
+		fields[f.Field] = f
+	}
+	codes.KeyParams = strings.Join(keyParams, ", ")
+
+	methods := repositoryMethodsOf(table, javaFields)
+	for _, fk := range table.ForeignKeys {
+		if len(fk.Columns) != 1 {
+			continue
+		}
+		if f, ok := fields[fieldNameOf(table.Status.Name, fk.Columns[0])]; ok {
+			methods = append(methods,
+				repositoryMethod{name: "listBy" + firstUpCase(f.Field), kind: "list", columns: fk.Columns},
+				repositoryMethod{name: "deleteBy" + firstUpCase(f.Field), kind: "delete", columns: fk.Columns},
+			)
+		}
+	}
+
+	seen := map[string]bool{"insert": true, "insertBatch": true, "updateByIdSelective": true, "selectById": true, "deleteById": true}
+	var methodCodes, statements strings.Builder
+	for _, m := range methods {
+		id := mybatisStatementId(m)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		params := make([]string, 0, len(m.columns))
+		conditions := make([]string, 0, len(m.columns))
+		for _, column := range m.columns {
+			f, c := fields[fieldNameOf(table.Status.Name, column)], columns[fieldNameOf(table.Status.Name, column)]
+			imports = append(append(imports, f.PackageName), f.Imports...)
+			if m.kind == "listIn" {
+				imports = append(imports, "java.util.Collection")
+				params = append(params, fmt.Sprintf("@Param(%s) Collection<%s> %ss", javaStringLiteral(f.Field+"s"), f.JavaType, f.Field))
+				conditions = append(conditions, fmt.Sprintf("%s IN\n    <foreach collection=%q item=%q open=\"(\" separator=\",\" close=\")\">\n      %s\n    </foreach>",
+					c.Quoted(), f.Field+"s", f.Field, c.Param("")))
+				continue
+			}
+			params = append(params, fmt.Sprintf("@Param(%s) %s %s", javaStringLiteral(f.Field), f.JavaType, f.Field))
+			conditions = append(conditions, fmt.Sprintf("%s = %s", c.Quoted(), c.Param("")))
+		}
+		where := strings.Join(conditions, "\n      AND ")
+
+		methodCodes.WriteString("\n")
+		statements.WriteString("\n")
+		switch m.kind {
+		case "find":
+			methodCodes.WriteString(fmt.Sprintf("  %s %s(%s);\n", names.Po, id, strings.Join(params, ", ")))
+		case "exists", "delete":
+			methodCodes.WriteString(fmt.Sprintf("  int %s(%s);\n", id, strings.Join(params, ", ")))
+		default:
+			methodCodes.WriteString(fmt.Sprintf("  List<%s> %s(%s);\n", names.Po, id, strings.Join(params, ", ")))
+		}
+		switch m.kind {
+		case "exists":
+			statements.WriteString(fmt.Sprintf("  <select id=%q resultType=\"int\">\n    SELECT COUNT(*)\n    FROM %s\n    WHERE %s\n  </select>\n", id, codes.Table, where))
+		case "delete":
+			statements.WriteString(fmt.Sprintf("  <delete id=%q>\n    DELETE FROM %s\n    WHERE %s\n  </delete>\n", id, codes.Table, where))
+		default:
+			statements.WriteString(fmt.Sprintf("  <select id=%q resultMap=\"BaseResultMap\">\n    SELECT <include refid=\"Base_Column_List\"/>\n    FROM %s\n    WHERE %s\n  </select>\n", id, codes.Table, where))
+		}
+	}
+	imports = slices.DeleteFunc(imports, func(pkg string) bool { return pkg == "" })
+	codes.Imports = sortJavaImports(imports)
+	codes.Methods = methodCodes.String()
+	codes.Statements = statements.String()
+	return codes
+}
+
+// mybatisStatementId returns the mapper method a repository method calls,
+// e.g. findByCode -> selectByCode, existsByCode -> countByCode, listByStatus -> selectListByStatus.
+func mybatisStatementId(m repositoryMethod) string {
+	switch m.kind {
+	case "find":
+		return "select" + strings.TrimPrefix(m.name, "find")
+	case "exists":
+		return "count" + strings.TrimPrefix(m.name, "exists")
+	case "list":
+		return "selectList" + strings.TrimPrefix(m.name, "list")
+	case "listIn":
+		return "select" + strings.TrimPrefix(m.name, "list")
+	}
+	return m.name
+}
+
+// mybatisRepositoryMethodBody returns the statements of a repository method calling the mapper.
+func mybatisRepositoryMethodBody(m repositoryMethod, args []string, factoryClassName, mapperFieldName string) string {
+	call := fmt.Sprintf("%s.%s(%s)", mapperFieldName, mybatisStatementId(m), strings.Join(args, ", "))
+	switch m.kind {
+	case "find":
+		return fmt.Sprintf("    return Optional.ofNullable(%s.fromPo(%s));\n", factoryClassName, call)
+	case "exists":
+		return fmt.Sprintf("    return %s > 0;\n", call)
+	}
+	return fmt.Sprintf("    return %s.fromPos(%s);\n", factoryClassName, call)
+}
+
+// parseMybatisAggregateRepositoryCodes returns the imports, child mapper members and the methods loading and
+// saving an aggregate root with its children through the statements of the XML mappers.
+func parseMybatisAggregateRepositoryCodes(table *Table, pkField, pkType string, children []aggregateChild) (imports []string, fieldCodes, methodCodes string) {
+	names := templateNamesOf(table.Status.Name)
+	pkGetter := "get" + firstUpCase(pkField)
+	packages := layerPackages()
+	imports = []string{
+		fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+		fmt.Sprintf("%s.%s", packages.Factory, names.Factory),
+		"java.util.Optional",
+		"org.springframework.transaction.annotation.Transactional",
+	}
+
+	var fields, load, save strings.Builder
+	loadArgs := []string{"po"}
+	for _, child := range children {
+		childNames := templateNamesOf(child.Table.Status.Name)
+		childColumn := firstUpCase(fieldNameOf(child.Table.Status.Name, child.Column))
+		rootGetter := "get" + firstUpCase(fieldNameOf(table.Status.Name, child.RootColumn))
+		imports = append(imports,
+			fmt.Sprintf("%s.%s", packages.Mapper, childNames.Mapper),
+			fmt.Sprintf("%s.%s", packages.Factory, childNames.Factory),
+		)
+
+		fields.WriteString(fmt.Sprintf("\n  @Resource\n  %s %s %s;\n", repositoryMemberModifier(), childNames.Mapper, childNames.MapperField))
+
+		childPosName := firstLowCase(childNames.Entity) + "Pos"
+		loadArgs = append(loadArgs, childPosName)
+		load.WriteString(fmt.Sprintf("    var %s = %s.selectListBy%s(po.%s());\n", childPosName, childNames.MapperField, childColumn, rootGetter))
+
+		save.WriteString(fmt.Sprintf("    %s.deleteBy%s(po.%s());\n", childNames.MapperField, childColumn, rootGetter))
+		save.WriteString(fmt.Sprintf("    var %s = %s.toPos(aggregate.get%ss());\n", childPosName, childNames.Factory, childNames.Entity))
+		save.WriteString(fmt.Sprintf("    if (!%s.isEmpty()) {\n", childPosName))
+		save.WriteString(fmt.Sprintf("      %s.forEach(childPo -> childPo.set%s(po.%s()));\n", childPosName, childColumn, rootGetter))
+		save.WriteString(fmt.Sprintf("      %s.insertBatch(%s);\n    }\n", childNames.MapperField, childPosName))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  public Optional<%s> findAggregateBy%s(%s %s) {\n", names.Entity, firstUpCase(pkField), pkType, pkField))
+	b.WriteString(fmt.Sprintf("    var po = %s.selectById(%s);\n", names.MapperField, pkField))
+	b.WriteString("    if (po == null) {\n      return Optional.empty();\n    }\n")
+	b.WriteString(load.String())
+	b.WriteString(fmt.Sprintf("    return Optional.of(%s.fromPo(%s));\n  }\n", names.Factory, strings.Join(loadArgs, ", ")))
+
+	b.WriteString("\n  @Transactional(rollbackFor = Exception.class)\n")
+	b.WriteString(fmt.Sprintf("  public %s saveAggregate(%s aggregate) {\n", pkType, names.Entity))
+	b.WriteString(fmt.Sprintf("    var po = %s.toPo(aggregate);\n", names.Factory))
+	b.WriteString(fmt.Sprintf("    if (po.%s() == null) {\n      %s.insert(po);\n    } else {\n      %s.updateByIdSelective(po);\n    }\n", pkGetter, names.MapperField, names.MapperField))
+	b.WriteString(save.String())
+	b.WriteString(fmt.Sprintf("    return po.%s();\n  }\n", pkGetter))
+	return imports, fields.String(), b.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMapperInsertDefaults(t *testing.T) {
+	defer func(t, d string) { target, dialect = t, d }(target, dialect)
+	target = targetMybatis
+	ddl := "CREATE TABLE tb_user (id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(32) NOT NULL, " +
+		"ctime datetime NOT NULL DEFAULT CURRENT_TIMESTAMP, name_upper varchar(32) GENERATED ALWAYS AS (upper(name)))"
+	tests := []struct {
+		dialect string
+		want    []string
+		not     []string
+	}{
+		{dialectMySQL, []string{
+			"INSERT INTO `tb_user`\n    <trim prefix=\"(\" suffix=\")\" suffixOverrides=\",\">\n      `name`,\n      <if test=\"ctime != null\">`ctime`,</if>\n    </trim>",
+			"<trim prefix=\"VALUES (\" suffix=\")\" suffixOverrides=\",\">\n      #{name},\n      <if test=\"ctime != null\">#{ctime},</if>\n    </trim>",
+			"INSERT INTO `tb_user` (`name`, `ctime`)\n    VALUES",
+			"(#{po.name}, <choose><when test=\"po.ctime != null\">#{po.ctime}</when><otherwise>DEFAULT</otherwise></choose>)",
+		}, []string{"#{nameUpper},", "#{po.id}", "#{po.nameUpper}"}},
+		{dialectSqlite, []string{
+			"<if test=\"ctime != null\">\"ctime\",</if>",
+			"(#{po.name}, #{po.ctime})",
+		}, []string{"DEFAULT</otherwise>"}},
+	}
+	for _, tt := range tests {
+		dialect = tt.dialect
+		var codes string
+		for file, c := range renderTable(t, parseTable(t, ddl)) {
+			if strings.HasSuffix(file, "/UserMapper.xml") {
+				codes = c
+			}
+		}
+		if codes == "" {
+			t.Fatalf("%s: UserMapper.xml was not generated", tt.dialect)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(codes, want) {
+				t.Errorf("%s: UserMapper.xml does not contain %q:\n%s", tt.dialect, want, codes)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(codes, not) {
+				t.Errorf("%s: UserMapper.xml contains %q:\n%s", tt.dialect, not, codes)
+			}
+		}
+	}
+}
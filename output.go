@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	fileCreated   = "create"    // the file is missing
+	fileChanged   = "change"    // the file differs from the generated one
+	fileUnchanged = "unchanged" // the file matches the generated one
+	fileKept      = "keep"      // the file is left to the developers, see the write once templates
+)
+
+// plannedFile is a generated file compared with the one on disk before anything is written
+type plannedFile struct {
+	filename string
+	previous string // content on disk, empty if missing
+	codes    string // content to write
+	status   string
+	// the hand-written file the generated one is written beside as .new, if any
+	handWritten string
+}
+
+// dryRun and showDiff preview the generated files instead of writing them, see -dry-run and -diff
+var dryRun, showDiff bool
+
+// checkOnly compares the generated files with those on disk without writing anything, see the check command
+var checkOnly bool
+
+// staleFiles are the files the check command found missing or changed
+var staleFiles = make([]*plannedFile, 0)
+
+// fileCounts counts the generated files by status
+var fileCounts = make(map[string]int)
+
+// generatedDatePattern matches the @date line of the javadoc, which changes on every run
+var generatedDatePattern = regexp.MustCompile(`(?m)^ \* @date .*$`)
+
+// newPlannedFile compares a generated file with the one on disk, a file differing only by its @date is unchanged.
+func newPlannedFile(filename, previous string, exists bool, codes string) *plannedFile {
+	f := &plannedFile{filename: filename, previous: previous, codes: codes, status: fileChanged}
+	switch {
+	case !exists:
+		f.status = fileCreated
+	case previous == codes || withoutGeneratedDate(previous) == withoutGeneratedDate(codes):
+		f.status, f.codes = fileUnchanged, previous
+	}
+	return f
+}
+
+// withoutGeneratedDate blanks the @date lines of a file.
+func withoutGeneratedDate(codes string) string {
+	return generatedDatePattern.ReplaceAllString(codes, " * @date")
+}
+
+// emitFile writes a planned file, or prints what would change with -dry-run and -diff.
+// The check command only records the stale files, a hand-written file is never stale.
+func emitFile(f *plannedFile) error {
+	fileCounts[f.status]++
+	stale := f.status == fileCreated || f.status == fileChanged
+	if checkOnly {
+		if stale && f.handWritten == "" {
+			staleFiles = append(staleFiles, f)
+			fmt.Printf("stale: %-6s %s\n", f.status, f.filename)
+			printDiff(f)
+		}
+		return nil
+	}
+	if dryRun || showDiff {
+		if f.status != fileKept {
+			fmt.Printf("%-9s %s\n", f.status, f.filename)
+		}
+		if stale {
+			printDiff(f)
+		}
+		return nil
+	}
+	if f.status == fileKept {
+		return nil
+	}
+	if f.status != fileUnchanged {
+		if err := writeFile(filepath.Dir(f.filename), f.filename, f.codes); err != nil {
+			return err
+		}
+	}
+	if f.handWritten != "" {
+		fmt.Printf("%s was written by hand, generated %s instead\n", f.handWritten, filepath.Base(f.filename))
+	}
+	fmt.Printf("%s: %s\n", strings.TrimSuffix(filepath.Base(f.filename), filepath.Ext(f.filename)), f.filename)
+	return nil
+}
+
+// printDiff prints the unified diff of a file with -diff.
+func printDiff(f *plannedFile) {
+	if !showDiff {
+		return
+	}
+	oldName := strings.TrimPrefix(f.filename, "./")
+	if f.status == fileCreated {
+		oldName = ""
+	}
+	fmt.Print(unifiedDiff(oldName, strings.TrimPrefix(f.filename, "./"), f.previous, f.codes))
+}
+
+// fileCountSummary describes the files a dry run would write.
+func fileCountSummary() string {
+	return fmt.Sprintf("%d to create, %d to change, %d unchanged", fileCounts[fileCreated], fileCounts[fileChanged], fileCounts[fileUnchanged])
+}
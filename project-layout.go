@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ProjectLayout defines the packages of the generated classes and the util classes they depend on
+type ProjectLayout struct {
+	// BasePackage is the package of a domain, {domain} is replaced by the domain name.
+	// The directories of the generated files start from the segment holding {domain}.
+	BasePackage string        `json:"basePackage,omitempty" yaml:"basePackage,omitempty"`
+	Packages    LayerPackages `json:"packages,omitempty" yaml:"packages,omitempty"`
+	Utils       UtilClasses   `json:"utils,omitempty" yaml:"utils,omitempty"`
+}
+
+// LayerPackages defines the package of each layer relative to the base package, {domain} is replaced as well
+type LayerPackages struct {
+	Po         string `json:"po,omitempty" yaml:"po,omitempty"`
+	Mapper     string `json:"mapper,omitempty" yaml:"mapper,omitempty"`
+	Handler    string `json:"handler,omitempty" yaml:"handler,omitempty"`
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty"`
+	Factory    string `json:"factory,omitempty" yaml:"factory,omitempty"`
+	Entity     string `json:"entity,omitempty" yaml:"entity,omitempty"`
+	Enum       string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Service    string `json:"service,omitempty" yaml:"service,omitempty"`
+	Assembler  string `json:"assembler,omitempty" yaml:"assembler,omitempty"`
+	Command    string `json:"command,omitempty" yaml:"command,omitempty"`
+}
+
+// UtilClasses defines the fully qualified names of the classes the generated code builds on
+type UtilClasses struct {
+	BasePo       string   `json:"basePo" yaml:"basePo"`             // super class of every PO, none if empty
+	BasePoFields []string `json:"basePoFields" yaml:"basePoFields"` // columns the super class of the PO declares
+	BeanCopy     string   `json:"beanCopy" yaml:"beanCopy"`         // class with a static copy(source, targetClass)
+}
+
+// defaultProjectLayout returns the layout of the projects this tool was written for.
+func defaultProjectLayout() *ProjectLayout {
+	return &ProjectLayout{
+		BasePackage: "com.mahuafm.phoenix.{domain}",
+		Packages: LayerPackages{
+			Po:         "infrastructure.persistence.po",
+			Mapper:     "infrastructure.persistence.mapper",
+			Handler:    "infrastructure.persistence.handler",
+			Repository: "infrastructure.repository",
+			Factory:    "infrastructure.factory",
+			Entity:     "domain.{domain}.entity",
+			Enum:       "domain.{domain}.enums",
+			Service:    "application.service",
+			Assembler:  "application.assembler",
+			Command:    "application.command",
+		},
+		Utils: UtilClasses{
+			BasePo:       "com.mahuafm.phoenix.util.infrastructure.persistence.po.base.BaseAutoIdPo",
+			BasePoFields: []string{"id", "ctime", "mtime"},
+			BeanCopy:     "com.mahuafm.phoenix.util.bean.BeanCopyUtil",
+		},
+	}
+}
+
+// layout holds the project layout, the defaults overridden by -layout and -package
+var layout = defaultProjectLayout()
+
+// readProjectLayout loads a JSON or YAML layout file over the defaults.
+func readProjectLayout(filename string) (*ProjectLayout, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	l := defaultProjectLayout()
+	if isYAMLFile(filename) {
+		err = yaml.Unmarshal(content, l)
+	} else {
+		err = json.Unmarshal(content, l)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if err = l.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return l, nil
+}
+
+// validate checks every package is a dotted Java name once {domain} is replaced.
+func (l *ProjectLayout) validate() error {
+	packages := map[string]string{
+		"basePackage":         l.BasePackage,
+		"packages.po":         l.Packages.Po,
+		"packages.mapper":     l.Packages.Mapper,
+		"packages.handler":    l.Packages.Handler,
+		"packages.repository": l.Packages.Repository,
+		"packages.factory":    l.Packages.Factory,
+		"packages.entity":     l.Packages.Entity,
+		"packages.enum":       l.Packages.Enum,
+		"packages.service":    l.Packages.Service,
+		"packages.assembler":  l.Packages.Assembler,
+		"packages.command":    l.Packages.Command,
+	}
+	for _, key := range sortedKeys(packages) {
+		if !isJavaPackageName(strings.ReplaceAll(packages[key], "{domain}", "domain")) {
+			return fmt.Errorf("%s: invalid package %q", key, packages[key])
+		}
+	}
+	for key, class := range map[string]string{"utils.basePo": l.Utils.BasePo, "utils.beanCopy": l.Utils.BeanCopy} {
+		if class != "" && !isJavaPackageName(class) {
+			return fmt.Errorf("%s: invalid class %q", key, class)
+		}
+	}
+	if l.Utils.BeanCopy == "" {
+		return fmt.Errorf("utils.beanCopy: missing")
+	}
+	return nil
+}
+
+// isJavaPackageName reports whether s is a dotted name of Java identifiers.
+func isJavaPackageName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || ('0' <= part[0] && part[0] <= '9') {
+			return false
+		}
+		for _, c := range part {
+			if !(c == '_' || c == '$' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// resolve returns the fully qualified package of every layer of a domain.
+func (l *ProjectLayout) resolve(domain string) LayerPackages {
+	base := strings.ReplaceAll(l.BasePackage, "{domain}", domain)
+	pkg := func(layer string) string {
+		return base + "." + strings.ReplaceAll(layer, "{domain}", domain)
+	}
+	return LayerPackages{
+		Po:         pkg(l.Packages.Po),
+		Mapper:     pkg(l.Packages.Mapper),
+		Handler:    pkg(l.Packages.Handler),
+		Repository: pkg(l.Packages.Repository),
+		Factory:    pkg(l.Packages.Factory),
+		Entity:     pkg(l.Packages.Entity),
+		Enum:       pkg(l.Packages.Enum),
+		Service:    pkg(l.Packages.Service),
+		Assembler:  pkg(l.Packages.Assembler),
+		Command:    pkg(l.Packages.Command),
+	}
+}
+
+// packageDir returns the directory of a package relative to the output directory, e.g.
+// com.mahuafm.phoenix.user.infrastructure.factory -> user/infrastructure/factory for com.mahuafm.phoenix.{domain}.
+// The source roots of -project take the whole package.
+func (l *ProjectLayout) packageDir(pkg string) string {
+	if project != nil {
+		return path.Join(strings.Split(pkg, ".")...)
+	}
+	base := l.BasePackage
+	if i := strings.Index(base, "{domain}"); i >= 0 {
+		base = base[:i]
+	}
+	root := ""
+	if i := strings.LastIndexByte(base, '.'); i >= 0 {
+		root = base[:i]
+	}
+	if root != "" && strings.HasPrefix(pkg, root+".") {
+		pkg = strings.TrimPrefix(pkg, root+".")
+	}
+	return path.Join(strings.Split(pkg, ".")...)
+}
+
+// basePoFields returns the members the PO inherits and the entity leaves out.
+func basePoFields() []string {
+	if layout.Utils.BasePo == "" {
+		return nil
+	}
+	return layout.Utils.BasePoFields
+}
+
+// basePoIdType is the type of the id the base PO declares, an auto increment Long like BaseAutoIdPo
+const basePoIdType = "Long"
+
+// conflictsWithBasePo reports whether the primary key of a table is one of the base PO fields but maps to another
+// type than its id, e.g. the BigInteger of a bigint unsigned. The PO of such a table declares every column instead.
+func conflictsWithBasePo(table *Table) bool {
+	pk := primaryKeyColumn(table)
+	if pk == "" || !slices.Contains(basePoFields(), fieldNameOf(table.Status.Name, pk)) {
+		return false
+	}
+	for _, col := range table.Columns {
+		if col.Field == pk {
+			javaType, _ := javaTypeMapper.javaType(table.Status.Name, col)
+			return javaType != basePoIdType
+		}
+	}
+	return false
+}
+
+// layerPackages returns the packages of the domain being generated.
+func layerPackages() LayerPackages {
+	return layout.resolve(domainName)
+}
+
+// simpleClassName returns the class name of a fully qualified name, e.g. java.util.List -> List.
+func simpleClassName(class string) string {
+	return class[strings.LastIndexByte(class, '.')+1:]
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestConflictsWithBasePo(t *testing.T) {
+	defer func(d string, m *typeMapper) { dialect, javaTypeMapper = d, m }(dialect, javaTypeMapper)
+	dialect = dialectMySQL
+	var err error
+	if javaTypeMapper, err = newTypeMapper(dialect, &TypeMapping{Fields: map[string]string{"tb_tag.id": "Long"}}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ddl  string
+		want bool
+	}{
+		{"CREATE TABLE tb_user (id bigint NOT NULL AUTO_INCREMENT PRIMARY KEY)", false},
+		{"CREATE TABLE tb_user (id bigint unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY)", true},
+		{"CREATE TABLE tb_user (id varchar(32) NOT NULL PRIMARY KEY)", true},
+		{"CREATE TABLE tb_tag (id bigint unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY)", false},
+		{"CREATE TABLE tb_user (user_id int NOT NULL PRIMARY KEY)", false},
+		{"CREATE TABLE tb_user_role (user_id int NOT NULL, role_id int NOT NULL, PRIMARY KEY (user_id, role_id))", false},
+	}
+	for _, tt := range tests {
+		if got := conflictsWithBasePo(parseTable(t, tt.ddl)); got != tt.want {
+			t.Errorf("conflictsWithBasePo(%s) = %v, want %v", tt.ddl, got, tt.want)
+		}
+	}
+
+	defer func(basePo string) { layout.Utils.BasePo = basePo }(layout.Utils.BasePo)
+	layout.Utils.BasePo = ""
+	if conflictsWithBasePo(parseTable(t, tests[1].ddl)) {
+		t.Errorf("conflictsWithBasePo without a base PO = true")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	buildMaven  = "maven"
+	buildGradle = "gradle"
+)
+
+// javaProject is a Maven or Gradle project the generated files are written into with -project
+type javaProject struct {
+	dir     string
+	build   string
+	modules []string // module directories relative to dir, empty for a single module project
+}
+
+// project is the project being generated into, nil unless -project is given
+var project *javaProject
+
+var (
+	mavenModulePattern   = regexp.MustCompile(`<module>\s*([^<\s]+)\s*</module>`)
+	gradleIncludePattern = regexp.MustCompile(`(?m)^\s*include\b(.*)$`)
+	gradleNamePattern    = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// detectJavaProject reads the build files of a project directory, Maven modules are read recursively.
+func detectJavaProject(dir string) (*javaProject, error) {
+	p := &javaProject{dir: dir}
+	switch {
+	case fileExists(filepath.Join(dir, "pom.xml")):
+		p.build = buildMaven
+		modules, err := readMavenModules(dir, "")
+		if err != nil {
+			return nil, err
+		}
+		p.modules = modules
+	case fileExists(filepath.Join(dir, "build.gradle")), fileExists(filepath.Join(dir, "build.gradle.kts")),
+		fileExists(filepath.Join(dir, "settings.gradle")), fileExists(filepath.Join(dir, "settings.gradle.kts")):
+		p.build = buildGradle
+		for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			p.modules = append(p.modules, parseGradleModules(string(content))...)
+		}
+	default:
+		return nil, fmt.Errorf("%s: no pom.xml or build.gradle found", dir)
+	}
+	return p, nil
+}
+
+// readMavenModules returns the modules a pom.xml declares and the modules of those, relative to the project.
+func readMavenModules(dir, module string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, module, "pom.xml"))
+	if err != nil {
+		return nil, err
+	}
+	modules := make([]string, 0)
+	for _, m := range mavenModulePattern.FindAllStringSubmatch(string(content), -1) {
+		child := path.Join(module, m[1])
+		modules = append(modules, child)
+		if fileExists(filepath.Join(dir, child, "pom.xml")) {
+			nested, err := readMavenModules(dir, child)
+			if err != nil {
+				return nil, err
+			}
+			modules = append(modules, nested...)
+		}
+	}
+	return modules, nil
+}
+
+// parseGradleModules returns the projects a settings.gradle includes, e.g. include ':order:order-domain' -> order/order-domain.
+func parseGradleModules(settings string) []string {
+	modules := make([]string, 0)
+	for _, include := range gradleIncludePattern.FindAllStringSubmatch(settings, -1) {
+		for _, name := range gradleNamePattern.FindAllStringSubmatch(include[1], -1) {
+			modules = append(modules, strings.ReplaceAll(strings.TrimPrefix(name[1], ":"), ":", "/"))
+		}
+	}
+	return modules
+}
+
+// moduleOf returns the module a file belongs to, "" for the project itself. A module belongs to a layer by the last
+// part of its name, e.g. order-domain, the module of the layer named first in the package after the base one wins,
+// one named after the domain preferred.
+func (p *javaProject) moduleOf(rel string) string {
+	segments := strings.Split(path.Dir(rel), "/")
+	base := strings.Split(strings.ReplaceAll(strings.ReplaceAll(layout.BasePackage, "{domain}", domainName), ".", "/"), "/")
+	if len(segments) > len(base) && strings.Join(segments[:len(base)], "/") == strings.Join(base, "/") {
+		segments = segments[len(base):]
+	}
+
+	best, bestIndex, bestDomain := "", len(segments), false
+	for _, module := range p.modules {
+		name := path.Base(module)
+		layer := name[strings.LastIndexByte(name, '-')+1:]
+		for i, segment := range segments {
+			if segment != layer {
+				continue
+			}
+			named := strings.Contains(name, domainName)
+			if i < bestIndex || (i == bestIndex && named && !bestDomain) {
+				best, bestIndex, bestDomain = module, i, named
+			}
+			break
+		}
+	}
+	return best
+}
+
+// resolve returns the file a generated file relative to the source root is written to,
+// .java files go to src/main/java, .kt files to src/main/kotlin and the others to src/main/resources.
+func (p *javaProject) resolve(rel string) string {
+	root := "src/main/resources"
+	switch strings.ToLower(path.Ext(rel)) {
+	case ".java":
+		root = "src/main/java"
+	case ".kt":
+		root = "src/main/kotlin"
+	}
+	return filepath.Join(p.dir, filepath.FromSlash(p.moduleOf(rel)), filepath.FromSlash(root), filepath.FromSlash(rel))
+}
+
+// outputPath returns the file a template path is written to, under -out or the source roots of -project.
+func outputPath(rel string) string {
+	filename := filepath.Join(outputDir, filepath.FromSlash(rel))
+	if project != nil {
+		filename = project.resolve(rel)
+	}
+	if !filepath.IsAbs(filename) && !strings.HasPrefix(filename, "..") {
+		filename = "./" + filename
+	}
+	return filename
+}
+
+// fileExists reports whether a regular file exists.
+func fileExists(filename string) bool {
+	info, err := os.Stat(filename)
+	return err == nil && !info.IsDir()
+}
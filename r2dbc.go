@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseReactiveAggregateRepositoryCodes returns the imports, child R2DBC repository members and the methods loading
+// and saving an aggregate root with its children through reactive Spring Data repositories.
+func parseReactiveAggregateRepositoryCodes(table *Table, pkField, pkType string, children []aggregateChild) (imports []string, fieldCodes, methodCodes string) {
+	names := templateNamesOf(table.Status.Name)
+	pkGetter := "get" + firstUpCase(pkField)
+	packages := layerPackages()
+	imports = []string{
+		fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+		fmt.Sprintf("%s.%s", packages.Factory, names.Factory),
+		"org.springframework.transaction.annotation.Transactional",
+		"reactor.core.publisher.Mono",
+	}
+
+	var fields, save strings.Builder
+	loads := make([]string, 0, len(children))
+	loadArgs := []string{"po"}
+	for i, child := range children {
+		childNames := templateNamesOf(child.Table.Status.Name)
+		childColumn := firstUpCase(fieldNameOf(child.Table.Status.Name, child.Column))
+		rootGetter := "get" + firstUpCase(fieldNameOf(table.Status.Name, child.RootColumn))
+		imports = append(imports,
+			fmt.Sprintf("%s.%s", packages.Mapper, childNames.Mapper),
+			fmt.Sprintf("%s.%s", packages.Factory, childNames.Factory),
+		)
+
+		fields.WriteString(fmt.Sprintf("\n  @Resource\n  %s %s %s;\n", repositoryMemberModifier(), childNames.Mapper, childNames.MapperField))
+
+		loads = append(loads, fmt.Sprintf("%s.findBy%s(po.%s()).collectList()", childNames.MapperField, childColumn, rootGetter))
+		if len(children) == 1 {
+			loadArgs = append(loadArgs, firstLowCase(childNames.Entity)+"Pos")
+		} else {
+			loadArgs = append(loadArgs, fmt.Sprintf("children.getT%d()", i+1))
+		}
+
+		childPosName := firstLowCase(childNames.Entity) + "Pos"
+		save.WriteString(fmt.Sprintf("          var %s = %s.toPos(aggregate.get%ss());\n", childPosName, childNames.Factory, childNames.Entity))
+		save.WriteString(fmt.Sprintf("          %s.forEach(childPo -> childPo.set%s(po.%s()));\n", childPosName, childColumn, rootGetter))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  public Mono<%s> findAggregateBy%s(%s %s) {\n", names.Entity, firstUpCase(pkField), pkType, pkField))
+	b.WriteString(fmt.Sprintf("    return %s.findById(%s)\n", names.MapperField, pkField))
+	if len(children) == 1 {
+		b.WriteString(fmt.Sprintf("        .flatMap(po -> %s\n", loads[0]))
+		b.WriteString(fmt.Sprintf("            .map(%s -> %s.fromPo(%s)));\n  }\n", loadArgs[1], names.Factory, strings.Join(loadArgs, ", ")))
+	} else {
+		b.WriteString("        .flatMap(po -> Mono.zip(\n")
+		b.WriteString("                " + strings.Join(loads, ",\n                ") + ")\n")
+		b.WriteString(fmt.Sprintf("            .map(children -> %s.fromPo(%s)));\n  }\n", names.Factory, strings.Join(loadArgs, ", ")))
+	}
+
+	b.WriteString("\n  @Transactional(rollbackFor = Exception.class)\n")
+	b.WriteString(fmt.Sprintf("  public Mono<%s> saveAggregate(%s aggregate) {\n", pkType, names.Entity))
+	b.WriteString(fmt.Sprintf("    return %s.save(%s.toPo(aggregate))\n", names.MapperField, names.Factory))
+	b.WriteString("        .flatMap(po -> {\n")
+	b.WriteString(save.String())
+	for i, child := range children {
+		childNames := templateNamesOf(child.Table.Status.Name)
+		childColumn := firstUpCase(fieldNameOf(child.Table.Status.Name, child.Column))
+		rootGetter := "get" + firstUpCase(fieldNameOf(table.Status.Name, child.RootColumn))
+		deleteAll := fmt.Sprintf("%s.deleteAll(%s.findBy%s(po.%s()))", childNames.MapperField, childNames.MapperField, childColumn, rootGetter)
+		if i == 0 {
+			b.WriteString(fmt.Sprintf("          return %s\n", deleteAll))
+		} else {
+			b.WriteString(fmt.Sprintf("              .then(%s)\n", deleteAll))
+		}
+		b.WriteString(fmt.Sprintf("              .thenMany(%s.saveAll(%sPos))\n", childNames.MapperField, firstLowCase(childNames.Entity)))
+	}
+	b.WriteString(fmt.Sprintf("              .then(Mono.just(po.%s()));\n        });\n  }\n", pkGetter))
+	return imports, fields.String(), b.String()
+}
+
+// parseReactiveServiceCodes returns the imports and the methods of an app service of the r2dbc target delegating
+// to the reactive query methods of the repository, and to the aggregate ones of an aggregate root.
+func parseReactiveServiceCodes(table *Table, javaFields []JavaField, children []aggregateChild, names templateNames) (imports []string, methodCodes string) {
+	methods := repositoryMethodsOf(table, javaFields)
+	var b strings.Builder
+	delegate := func(returnType, name string, params, args []string) {
+		b.WriteString(fmt.Sprintf("\n  public %s %s(%s) {\n", returnType, name, strings.Join(params, ", ")))
+		b.WriteString(fmt.Sprintf("    return %s.%s(%s);\n  }\n", names.RepositoryField, name, strings.Join(args, ", ")))
+	}
+	for _, m := range methods {
+		params, args := repositoryMethodParams(table, javaFields, m)
+		delegate(repositoryReturnType(m, names.Entity), m.name, params, args)
+	}
+	if len(methods) > 0 {
+		imports = append(repositoryMethodImports(table, javaFields, methods), fmt.Sprintf("%s.%s", layerPackages().Entity, names.Entity))
+		imports = slices.DeleteFunc(imports, func(pkg string) bool { return pkg == "org.springframework.util.CollectionUtils" })
+	}
+
+	pk := primaryKeyColumn(table)
+	if pk == "" || len(children) == 0 {
+		return imports, b.String()
+	}
+	pkField := fieldNameOf(table.Status.Name, pk)
+	pkType := "Long"
+	for _, f := range javaFields {
+		if f.Field == pkField {
+			pkType = f.JavaType
+		}
+	}
+	delegate(fmt.Sprintf("Mono<%s>", names.Entity), "findAggregateBy"+firstUpCase(pkField), []string{pkType + " " + pkField}, []string{pkField})
+	delegate(fmt.Sprintf("Mono<%s>", pkType), "saveAggregate", []string{names.Entity + " aggregate"}, []string{"aggregate"})
+	imports = append(imports, fmt.Sprintf("%s.%s", layerPackages().Entity, names.Entity), "reactor.core.publisher.Mono")
+	return imports, b.String()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReactiveCodes(t *testing.T) {
+	defer func(t, d string) { target, dialect = t, d }(target, dialect)
+	target, dialect = targetR2dbc, dialectMySQL
+	files := renderTable(t, parseTable(t, "CREATE TABLE tb_user (id bigint unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(32), KEY idx_name (name))"))
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"user/infrastructure/repository/UserRepository.java", []string{
+			"import java.math.BigInteger;",
+			"public Mono<User> findById(BigInteger id) {\n    return userR2dbcRepository.findById(id).map(UserFactory::fromPo);",
+			"public Mono<Boolean> existsById(BigInteger id) {\n    return userR2dbcRepository.existsById(id);",
+			"public Flux<User> listByIds(Collection<BigInteger> ids) {\n    if (CollectionUtils.isEmpty(ids)) {\n      return Flux.empty();",
+			"public Flux<User> listByName(String name) {\n    return userR2dbcRepository.findByName(name).map(UserFactory::fromPo);",
+		}},
+		{"user/application/service/UserAppService.java", []string{
+			"import java.math.BigInteger;",
+			"public Mono<User> findById(BigInteger id) {\n    return userRepository.findById(id);",
+			"public Flux<User> listByName(String name) {",
+		}},
+	}
+	for _, tt := range tests {
+		codes, ok := files[tt.file]
+		if !ok {
+			t.Errorf("%s was not generated", tt.file)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(codes, want) {
+				t.Errorf("%s does not contain %q:\n%s", tt.file, want, codes)
+			}
+		}
+	}
+}
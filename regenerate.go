@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// generatedMarker marks the files this tool generated, a file without it was written by hand
+const generatedMarker = "@generated by springboot-ddd-gen-mysql, only the user-code regions are kept when regenerating"
+
+const (
+	conflictNew    = "new"    // a hand-written file is left alone, the generated one is written beside it as .new
+	conflictRefuse = "refuse" // a hand-written file fails the table
+)
+
+// conflictMode decides what happens to the hand-written files, see -conflict
+var conflictMode = conflictNew
+
+// userCodeStartPattern matches the first line of a user-code region, e.g. "// region user-code members"
+var userCodeStartPattern = regexp.MustCompile(`^\s*(?://|#|--|<!--)\s*region user-code\b\s*(.*?)\s*(?:-->)?\s*$`)
+
+// userCodeEndPattern matches the last line of a region
+var userCodeEndPattern = regexp.MustCompile(`^\s*(?://|#|--|<!--)\s*endregion\b`)
+
+// commentLine returns a line comment in the syntax of a file, by its extension.
+func commentLine(filename, text string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xml", ".html":
+		return "<!-- " + text + " -->"
+	case ".yaml", ".yml", ".properties", ".sh":
+		return "# " + text
+	case ".sql":
+		return "-- " + text
+	default:
+		return "// " + text
+	}
+}
+
+// markGenerated puts the generated marker on top of a file, below the XML declaration if any.
+func markGenerated(filename, codes string) string {
+	marker := commentLine(filename, generatedMarker) + "\n"
+	if strings.HasPrefix(codes, "<?xml") {
+		if i := strings.IndexByte(codes, '\n'); i >= 0 {
+			return codes[:i+1] + marker + codes[i+1:]
+		}
+	}
+	return marker + codes
+}
+
+// isGenerated reports whether a file carries the generated marker in its first lines.
+func isGenerated(codes string) bool {
+	lines := strings.SplitN(codes, "\n", 4)
+	for _, line := range lines[:min(len(lines), 3)] {
+		if strings.Contains(line, generatedMarker) {
+			return true
+		}
+	}
+	return false
+}
+
+// userCodeRegion holds the lines of a user-code region between its first and last line
+type userCodeRegion struct {
+	name  string
+	lines []string
+}
+
+// parseUserCodeRegions returns the user-code regions of a file in order.
+func parseUserCodeRegions(codes string) ([]userCodeRegion, error) {
+	regions := make([]userCodeRegion, 0)
+	var open *userCodeRegion
+	for i, line := range strings.Split(codes, "\n") {
+		if m := userCodeStartPattern.FindStringSubmatch(line); m != nil {
+			if open != nil {
+				return nil, fmt.Errorf("line %d: region user-code %s opened before the previous one ended", i+1, m[1])
+			}
+			open = &userCodeRegion{name: m[1]}
+			continue
+		}
+		if open != nil && userCodeEndPattern.MatchString(line) {
+			regions = append(regions, *open)
+			open = nil
+			continue
+		}
+		if open != nil {
+			open.lines = append(open.lines, line)
+		}
+	}
+	if open != nil {
+		return nil, fmt.Errorf("region user-code %s never ends", open.name)
+	}
+	return regions, nil
+}
+
+// mergeUserCode carries the user-code regions of the previous file over to the generated one,
+// regions are matched by name, those sharing a name in order.
+func mergeUserCode(previous, generated string) (string, error) {
+	regions, err := parseUserCodeRegions(previous)
+	if err != nil {
+		return "", err
+	}
+	kept := make(map[string][][]string)
+	for _, r := range regions {
+		kept[r.name] = append(kept[r.name], r.lines)
+	}
+
+	var b strings.Builder
+	lines := strings.Split(generated, "\n")
+	for i := 0; i < len(lines); i++ {
+		b.WriteString(lines[i])
+		if i < len(lines)-1 {
+			b.WriteByte('\n')
+		}
+		m := userCodeStartPattern.FindStringSubmatch(lines[i])
+		if m == nil || len(kept[m[1]]) == 0 {
+			continue
+		}
+		// replace the generated body by the kept one
+		for _, line := range kept[m[1]][0] {
+			b.WriteString(line + "\n")
+		}
+		kept[m[1]] = kept[m[1]][1:]
+		for i+1 < len(lines) && !userCodeEndPattern.MatchString(lines[i+1]) {
+			i++
+		}
+	}
+
+	for _, r := range regions {
+		if len(kept[r.name]) > 0 && strings.TrimSpace(strings.Join(kept[r.name][0], "")) != "" {
+			return "", fmt.Errorf("region user-code %s is no longer generated, move its code before regenerating", r.name)
+		}
+	}
+	return b.String(), nil
+}
+
+// planGenerated plans a generated file, keeping the user-code regions of the file it replaces.
+// A file written by hand is never overwritten, see conflictMode, the .new file beside it is marked in its syntax.
+func planGenerated(filename, codes string) (*plannedFile, error) {
+	codes = markGenerated(filename, codes)
+	previous, exists, err := readPrevious(filename)
+	if err != nil {
+		return nil, err
+	}
+	handWritten := ""
+	if exists && !isGenerated(previous) {
+		if conflictMode == conflictRefuse {
+			return nil, fmt.Errorf("%s was written by hand, not overwritten", filename)
+		}
+		handWritten, filename = filename, filename+".new"
+		if previous, exists, err = readPrevious(filename); err != nil {
+			return nil, err
+		}
+	}
+	if exists {
+		if codes, err = mergeUserCode(previous, codes); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	f := newPlannedFile(filename, previous, exists, codes)
+	f.handWritten = handWritten
+	return f, nil
+}
+
+// planOnce plans a file left to the developers, it carries no marker and is never written again.
+// A file still marked as generated was generated in full before and is replaced, keeping its user-code regions.
+func planOnce(filename, codes string) (*plannedFile, error) {
+	previous, exists, err := readPrevious(filename)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if !isGenerated(previous) {
+			return &plannedFile{filename: filename, previous: previous, codes: previous, status: fileKept}, nil
+		}
+		if codes, err = mergeUserCode(previous, codes); err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+	return newPlannedFile(filename, previous, exists, codes), nil
+}
+
+// readPrevious reads the file a generated one replaces, if any.
+func readPrevious(filename string) (string, bool, error) {
+	previous, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(previous), true, nil
+}
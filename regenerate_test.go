@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMarkGenerated(t *testing.T) {
+	tests := []struct {
+		filename, codes, want string
+	}{
+		{"User.java", "package a;\n", "// " + generatedMarker + "\npackage a;\n"},
+		{"UserMapper.xml", "<?xml version=\"1.0\"?>\n<mapper/>\n", "<?xml version=\"1.0\"?>\n<!-- " + generatedMarker + " -->\n<mapper/>\n"},
+		{"application.yml", "a: 1\n", "# " + generatedMarker + "\na: 1\n"},
+		{"schema.sql", "", "-- " + generatedMarker + "\n"},
+	}
+	for _, tt := range tests {
+		got := markGenerated(tt.filename, tt.codes)
+		if got != tt.want {
+			t.Errorf("markGenerated(%s) = %q, want %q", tt.filename, got, tt.want)
+		}
+		if !isGenerated(got) {
+			t.Errorf("isGenerated(markGenerated(%s)) = false", tt.filename)
+		}
+	}
+	if isGenerated("a\nb\nc\n// " + generatedMarker) {
+		t.Errorf("isGenerated found the marker below the first lines")
+	}
+}
+
+func TestParseUserCodeRegions(t *testing.T) {
+	regions, err := parseUserCodeRegions("a\n  // region user-code imports\nimport x;\n  // endregion\n<!-- region user-code sql -->\n<sql/>\n<!-- endregion -->\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regions) != 2 || regions[0].name != "imports" || strings.Join(regions[0].lines, "|") != "import x;" ||
+		regions[1].name != "sql" || strings.Join(regions[1].lines, "|") != "<sql/>" {
+		t.Errorf("parseUserCodeRegions = %+v", regions)
+	}
+
+	tests := []struct {
+		codes, want string
+	}{
+		{"// region user-code a\n// region user-code b\n// endregion", "line 2: region user-code b opened before the previous one ended"},
+		{"// region user-code a\nx\n", "region user-code a never ends"},
+	}
+	for _, tt := range tests {
+		if _, err = parseUserCodeRegions(tt.codes); err == nil || err.Error() != tt.want {
+			t.Errorf("parseUserCodeRegions(%q) error = %v, want %s", tt.codes, err, tt.want)
+		}
+	}
+}
+
+func TestMergeUserCode(t *testing.T) {
+	generated := "class A {\n  // region user-code members\n  // endregion\n  int b;\n  // region user-code methods\n  // endregion\n}\n"
+	tests := []struct {
+		name, previous, want, err string
+	}{
+		{
+			name:     "regions kept",
+			previous: "class A {\n  // region user-code members\n  int x;\n  // endregion\n  int a;\n  // region user-code methods\n  void f() {}\n\n  // endregion\n}\n",
+			want:     "class A {\n  // region user-code members\n  int x;\n  // endregion\n  int b;\n  // region user-code methods\n  void f() {}\n\n  // endregion\n}\n",
+		},
+		{
+			name:     "empty regions",
+			previous: "class A {}\n",
+			want:     generated,
+		},
+		{
+			name:     "region dropped",
+			previous: "// region user-code members\n// endregion\n// region user-code helpers\nint y;\n// endregion\n",
+			err:      "region user-code helpers is no longer generated, move its code before regenerating",
+		},
+		{
+			name:     "blank region dropped",
+			previous: "// region user-code helpers\n\n// endregion\n",
+			want:     generated,
+		},
+		{
+			name:     "unbalanced",
+			previous: "// region user-code members\n",
+			err:      "region user-code members never ends",
+		},
+	}
+	for _, tt := range tests {
+		got, err := mergeUserCode(tt.previous, generated)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%s: error = %v, want %s", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("%s: mergeUserCode = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergeUserCodeSharedNames(t *testing.T) {
+	previous := "// region user-code body\n1\n// endregion\n// region user-code body\n2\n// endregion\n"
+	generated := "// region user-code body\n// endregion\nx\n// region user-code body\n// endregion\n"
+	got, err := mergeUserCode(previous, generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "// region user-code body\n1\n// endregion\nx\n// region user-code body\n2\n// endregion\n"; got != want {
+		t.Errorf("mergeUserCode = %q, want %q", got, want)
+	}
+}
+
+func TestPlanGenerated(t *testing.T) {
+	defer func(mode string) { conflictMode = mode }(conflictMode)
+	dir := t.TempDir()
+	generated := filepath.Join(dir, "User.java")
+	handWritten := filepath.Join(dir, "Order.java")
+	if err := os.WriteFile(generated, []byte(markGenerated(generated, "class User {\n// region user-code members\nint x;\n// endregion\n}\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(handWritten, []byte("class Order {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := planGenerated(generated, "class User {\n// region user-code members\n// endregion\nint y;\n}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.status != fileChanged || !strings.Contains(f.codes, "int x;\n// endregion\nint y;") {
+		t.Errorf("planGenerated(%s) = %s %q", generated, f.status, f.codes)
+	}
+
+	conflictMode = conflictNew
+	if f, err = planGenerated(handWritten, "class Order {}\n"); err != nil {
+		t.Fatal(err)
+	}
+	if f.filename != handWritten+".new" || f.handWritten != handWritten || f.status != fileCreated || strings.Count(f.codes, generatedMarker) != 1 {
+		t.Errorf("planGenerated(%s) = %+v", handWritten, f)
+	}
+
+	conflictMode = conflictRefuse
+	if _, err = planGenerated(handWritten, "class Order {}\n"); err == nil || !strings.HasSuffix(err.Error(), "was written by hand, not overwritten") {
+		t.Errorf("planGenerated(%s) error = %v, want a refusal", handWritten, err)
+	}
+}
+
+func TestPlanGeneratedBesideHandWritten(t *testing.T) {
+	defer func(mode string) { conflictMode = mode }(conflictMode)
+	conflictMode = conflictNew
+	tests := []struct {
+		name, codes, want string
+	}{
+		{"Order.java", "class Order {}\n", "// " + generatedMarker + "\nclass Order {}\n"},
+		{"OrderMapper.xml", "<?xml version=\"1.0\"?>\n<mapper/>\n", "<?xml version=\"1.0\"?>\n<!-- " + generatedMarker + " -->\n<mapper/>\n"},
+		{"application.yml", "a: 1\n", "# " + generatedMarker + "\na: 1\n"},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		filename := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(filename, []byte("written by hand\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := planGenerated(filename, tt.codes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.filename != filename+".new" || f.codes != tt.want {
+			t.Errorf("planGenerated(%s) = %s %q, want %s.new %q", tt.name, f.filename, f.codes, tt.name, tt.want)
+		}
+
+		// a .new file written before keeps its user-code regions like any generated file
+		if err = os.WriteFile(filename+".new", []byte(tt.want), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if f, err = planGenerated(filename, tt.codes); err != nil {
+			t.Fatal(err)
+		}
+		if f.filename != filename+".new" || f.status != fileUnchanged {
+			t.Errorf("planGenerated(%s) again = %s %s, want %s.new unchanged", tt.name, f.filename, f.status, tt.name)
+		}
+	}
+}
+
+func TestPlanOnce(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "UserService.java")
+	if f, err := planOnce(filename, "class UserService {}\n"); err != nil || f.status != fileCreated {
+		t.Fatalf("planOnce(%s) = %+v, %v", filename, f, err)
+	}
+	if err := os.WriteFile(filename, []byte("class UserService { void f() {} }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := planOnce(filename, "class UserService {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.status != fileKept || f.codes != "class UserService { void f() {} }\n" {
+		t.Errorf("planOnce(%s) = %+v, want the file kept", filename, f)
+	}
+}
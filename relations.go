@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// aggregateChild defines a table composed into an aggregate root through a single column foreign key
+type aggregateChild struct {
+	Table      *Table
+	Column     string // column of the child referencing the root, e.g. order_id
+	RootColumn string // referenced column of the root, e.g. id
+}
+
+// entityClassNameOf returns the domain entity class name of a table, e.g. tb_order_item -> OrderItem.
+func entityClassNameOf(tableName string) string {
+	if className := tableConfig(tableName).ClassName; className != "" {
+		return className
+	}
+	return firstUpCase(camelCase(tryRemoveTablePrefix(tableName)))
+}
+
+// primaryKeyColumn returns the primary key column of a table, or empty if the key is missing or composite.
+func primaryKeyColumn(table *Table) string {
+	for _, index := range table.Indexes {
+		if index.Primary && len(index.Columns) == 1 {
+			return index.Columns[0]
+		}
+	}
+	return ""
+}
+
+// inferForeignKeys adds the foreign keys a naming convention implies for schemas without real constraints.
+// The pattern holds a {table} placeholder, e.g. {table}_id makes order_id reference the primary key of
+// tb_order, t_order or order.
+func inferForeignKeys(tables []*Table, pattern string) {
+	if pattern == "" || !strings.Contains(pattern, "{table}") {
+		return
+	}
+	for _, table := range tables {
+		declared := make(map[string]bool)
+		for _, fk := range table.ForeignKeys {
+			if len(fk.Columns) == 1 {
+				declared[strings.ToLower(fk.Columns[0])] = true
+			}
+		}
+		for _, col := range table.Columns {
+			if strings.ToUpper(col.Key) == "PRI" || declared[strings.ToLower(col.Field)] {
+				continue
+			}
+			for _, parent := range tables {
+				pk := primaryKeyColumn(parent)
+				if parent == table || pk == "" {
+					continue
+				}
+				byEntity := strings.ReplaceAll(pattern, "{table}", tryRemoveTablePrefix(parent.Status.Name))
+				byTable := strings.ReplaceAll(pattern, "{table}", parent.Status.Name)
+				if !strings.EqualFold(col.Field, byEntity) && !strings.EqualFold(col.Field, byTable) {
+					continue
+				}
+				table.ForeignKeys = append(table.ForeignKeys, ForeignKeyStatement{
+					Name:              fmt.Sprintf("fk_inferred_%s_%s", table.Status.Name, col.Field),
+					Columns:           []string{col.Field},
+					ReferencedTable:   parent.Status.Name,
+					ReferencedColumns: []string{pk},
+				})
+				break
+			}
+		}
+	}
+}
+
+// resolveAggregates returns the children of every aggregate root, keyed by the root table name.
+// A table is composed into the table its foreign key references when its entity name extends the
+// referenced one, e.g. tb_order_item into tb_order, other foreign keys stay plain ID references.
+func resolveAggregates(tables []*Table) map[string][]aggregateChild {
+	byName := make(map[string]*Table)
+	for _, table := range tables {
+		byName[table.Status.Name] = table
+	}
+
+	aggregates := make(map[string][]aggregateChild)
+	for _, table := range tables {
+		var best *aggregateChild
+		var bestRoot string
+		for _, fk := range table.ForeignKeys {
+			root, ok := byName[fk.ReferencedTable]
+			if !ok || root == table || len(fk.Columns) != 1 || primaryKeyColumn(root) == "" {
+				continue
+			}
+			rootEntity := tryRemoveTablePrefix(root.Status.Name)
+			if !strings.HasPrefix(tryRemoveTablePrefix(table.Status.Name), rootEntity+"_") {
+				continue
+			}
+			// the longest root wins, tb_order_item_log belongs to tb_order_item rather than tb_order
+			if best == nil || len(rootEntity) > len(tryRemoveTablePrefix(bestRoot)) {
+				best = &aggregateChild{Table: table, Column: fk.Columns[0], RootColumn: fk.ReferencedColumns[0]}
+				bestRoot = root.Status.Name
+			}
+		}
+		if best != nil {
+			aggregates[bestRoot] = append(aggregates[bestRoot], *best)
+		}
+	}
+	for _, children := range aggregates {
+		sort.Slice(children, func(i, j int) bool { return children[i].Table.Status.Name < children[j].Table.Status.Name })
+	}
+	return aggregates
+}
+
+// aggregateCandidates returns the tables besides the selected ones that may be composed into one of them, those whose
+// entity name extends a selected one, e.g. tb_order_item and tb_order_item_log for tb_order.
+func aggregateCandidates(names, selected []string) []string {
+	roots := make([]string, 0, len(selected))
+	for _, name := range selected {
+		roots = append(roots, tryRemoveTablePrefix(name)+"_")
+	}
+	candidates := make([]string, 0)
+	for _, name := range names {
+		entity := tryRemoveTablePrefix(name)
+		if slices.Contains(selected, name) || !slices.ContainsFunc(roots, func(root string) bool { return strings.HasPrefix(entity, root) }) {
+			continue
+		}
+		candidates = append(candidates, name)
+	}
+	return candidates
+}
+
+// repositoryMemberModifier returns the access modifier of the mappers a repository holds, the abstract repository of
+// generation gap mode shares them with the hand-written one.
+func repositoryMemberModifier() string {
+	if generationGap {
+		return "protected"
+	}
+	return "private"
+}
+
+// parseAggregateEntityFields returns the child collection members of an aggregate root entity.
+func parseAggregateEntityFields(children []aggregateChild) []JavaField {
+	fields := make([]JavaField, 0, len(children))
+	for _, child := range children {
+		className := entityClassNameOf(child.Table.Status.Name)
+		fields = append(fields, JavaField{
+			JavaType:    fmt.Sprintf("List<%s>", className),
+			Field:       firstLowCase(className) + "s",
+			Comment:     child.Table.Status.Comment,
+			PackageName: "java.util.List",
+		})
+	}
+	return fields
+}
+
+// parseAggregateFactoryCodes returns the imports and the fromPo overload mapping the child POs of an aggregate root.
+func parseAggregateFactoryCodes(children []aggregateChild, entityClassName, poClassName string) (imports []string, codes string) {
+	params := []string{fmt.Sprintf("%s po", poClassName)}
+	setters := make([]string, 0, len(children))
+	for _, child := range children {
+		childClassName := entityClassNameOf(child.Table.Status.Name)
+		childPosName := firstLowCase(childClassName) + "Pos"
+		imports = append(imports, fmt.Sprintf("%s.%sPo", layerPackages().Po, childClassName))
+		params = append(params, fmt.Sprintf("List<%sPo> %s", childClassName, childPosName))
+		setters = append(setters, fmt.Sprintf("    entity.set%ss(%sFactory.fromPos(%s));", childClassName, childClassName, childPosName))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  public static %s fromPo(%s) {\n", entityClassName, strings.Join(params, ", ")))
+	b.WriteString("    var entity = fromPo(po);\n    if (entity == null) {\n      return null;\n    }\n")
+	b.WriteString(strings.Join(setters, "\n") + "\n")
+	b.WriteString("    return entity;\n  }\n")
+	return imports, b.String()
+}
+
+// parseAggregateRepositoryCodes returns the imports, child mapper members and the methods loading and saving
+// an aggregate root with its children.
+func parseAggregateRepositoryCodes(table *Table, javaFields []JavaField, children []aggregateChild) (imports []string, fieldCodes, methodCodes string) {
+	pk := primaryKeyColumn(table)
+	if pk == "" || len(children) == 0 {
+		return nil, "", ""
+	}
+	pkField := fieldNameOf(table.Status.Name, pk)
+	pkType := "Long"
+	for _, f := range javaFields {
+		if f.Field == pkField {
+			pkType = f.JavaType
+		}
+	}
+	switch target {
+	case targetJpa, targetJdbc:
+		return parseSpringDataAggregateRepositoryCodes(table, pkField, pkType, children)
+	case targetR2dbc:
+		return parseReactiveAggregateRepositoryCodes(table, pkField, pkType, children)
+	case targetMybatis:
+		return parseMybatisAggregateRepositoryCodes(table, pkField, pkType, children)
+	}
+	entityClassName := entityClassNameOf(table.Status.Name)
+	poClassName := entityClassName + "Po"
+	mapperFieldName := firstLowCase(entityClassName) + "Mapper"
+	pkGetter := "get" + firstUpCase(pkField)
+	packages := layerPackages()
+
+	imports = []string{
+		"com.baomidou.mybatisplus.core.toolkit.Wrappers",
+		fmt.Sprintf("%s.%s", packages.Entity, entityClassName),
+		fmt.Sprintf("%s.%sFactory", packages.Factory, entityClassName),
+		fmt.Sprintf("%s.%s", packages.Po, poClassName),
+		"java.util.Optional",
+		"org.springframework.transaction.annotation.Transactional",
+	}
+
+	var fields, load, save strings.Builder
+	loadArgs := []string{"po"}
+	for _, child := range children {
+		childClassName := entityClassNameOf(child.Table.Status.Name)
+		childPoClassName := childClassName + "Po"
+		childMapperFieldName := firstLowCase(childClassName) + "Mapper"
+		childGetter := fmt.Sprintf("%s::get%s", childPoClassName, firstUpCase(fieldNameOf(child.Table.Status.Name, child.Column)))
+		rootGetter := "get" + firstUpCase(fieldNameOf(table.Status.Name, child.RootColumn))
+		imports = append(imports,
+			fmt.Sprintf("%s.%sMapper", packages.Mapper, childClassName),
+			fmt.Sprintf("%s.%s", packages.Po, childPoClassName),
+			fmt.Sprintf("%s.%sFactory", packages.Factory, childClassName),
+		)
+
+		fields.WriteString(fmt.Sprintf("\n  @Resource\n  %s %sMapper %s;\n", repositoryMemberModifier(), childClassName, childMapperFieldName))
+
+		childPosName := firstLowCase(childClassName) + "Pos"
+		loadArgs = append(loadArgs, childPosName)
+		load.WriteString(fmt.Sprintf("    var %s = %s.selectList(Wrappers.<%s>lambdaQuery()\n        .eq(%s, po.%s()));\n",
+			childPosName, childMapperFieldName, childPoClassName, childGetter, rootGetter))
+
+		save.WriteString(fmt.Sprintf("    %s.delete(Wrappers.<%s>lambdaQuery()\n        .eq(%s, po.%s()));\n",
+			childMapperFieldName, childPoClassName, childGetter, rootGetter))
+		save.WriteString(fmt.Sprintf("    for (var childPo : %sFactory.toPos(aggregate.get%ss())) {\n", childClassName, childClassName))
+		save.WriteString(fmt.Sprintf("      childPo.set%s(po.%s());\n", firstUpCase(fieldNameOf(child.Table.Status.Name, child.Column)), rootGetter))
+		save.WriteString(fmt.Sprintf("      %s.insert(childPo);\n    }\n", childMapperFieldName))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  public Optional<%s> findAggregateBy%s(%s %s) {\n", entityClassName, firstUpCase(pkField), pkType, pkField))
+	b.WriteString(fmt.Sprintf("    var po = %s.selectById(%s);\n", mapperFieldName, pkField))
+	b.WriteString("    if (po == null) {\n      return Optional.empty();\n    }\n")
+	b.WriteString(load.String())
+	b.WriteString(fmt.Sprintf("    return Optional.of(%sFactory.fromPo(%s));\n  }\n", entityClassName, strings.Join(loadArgs, ", ")))
+
+	b.WriteString("\n  @Transactional(rollbackFor = Exception.class)\n")
+	b.WriteString(fmt.Sprintf("  public %s saveAggregate(%s aggregate) {\n", pkType, entityClassName))
+	b.WriteString(fmt.Sprintf("    var po = %sFactory.toPo(aggregate);\n", entityClassName))
+	b.WriteString(fmt.Sprintf("    if (po.%s() == null) {\n      %s.insert(po);\n    } else {\n      %s.updateById(po);\n    }\n", pkGetter, mapperFieldName, mapperFieldName))
+	b.WriteString(save.String())
+	b.WriteString(fmt.Sprintf("    return po.%s();\n  }\n", pkGetter))
+	return imports, fields.String(), b.String()
+}
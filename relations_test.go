@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAggregateCandidates(t *testing.T) {
+	names := []string{"tb_order", "tb_order_item", "tb_order_item_log", "tb_orders", "tb_user", "t_user_role", "user_address"}
+	tests := []struct {
+		selected []string
+		want     []string
+	}{
+		{[]string{"tb_order"}, []string{"tb_order_item", "tb_order_item_log"}},
+		{[]string{"tb_order", "tb_order_item"}, []string{"tb_order_item_log"}},
+		{[]string{"tb_user"}, []string{"t_user_role", "user_address"}},
+		{[]string{"tb_order_item_log"}, nil},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := aggregateCandidates(names, tt.selected); !slices.Equal(got, tt.want) {
+			t.Errorf("aggregateCandidates(%v) = %v, want %v", tt.selected, got, tt.want)
+		}
+	}
+}
+
+func TestResolveAggregates(t *testing.T) {
+	tables, err := parseDDL(`
+CREATE TABLE tb_order (id bigint PRIMARY KEY, user_id bigint);
+CREATE TABLE tb_order_item (id bigint PRIMARY KEY, order_id bigint, FOREIGN KEY (order_id) REFERENCES tb_order (id));
+CREATE TABLE tb_order_item_log (id bigint PRIMARY KEY, order_id bigint, order_item_id bigint,
+  FOREIGN KEY (order_id) REFERENCES tb_order (id), FOREIGN KEY (order_item_id) REFERENCES tb_order_item (id));
+CREATE TABLE tb_user (id bigint PRIMARY KEY);
+CREATE TABLE tb_user_tag (id bigint PRIMARY KEY, user_id bigint);
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	children := func(aggregates map[string][]aggregateChild, root string) []string {
+		names := make([]string, 0)
+		for _, child := range aggregates[root] {
+			names = append(names, child.Table.Status.Name+"."+child.Column)
+		}
+		return names
+	}
+
+	aggregates := resolveAggregates(tables)
+	if got := children(aggregates, "tb_order"); !slices.Equal(got, []string{"tb_order_item.order_id"}) {
+		t.Errorf("children of tb_order = %v", got)
+	}
+	if got := children(aggregates, "tb_order_item"); !slices.Equal(got, []string{"tb_order_item_log.order_item_id"}) {
+		t.Errorf("children of tb_order_item = %v", got)
+	}
+	if got := children(aggregates, "tb_user"); len(got) != 0 {
+		t.Errorf("children of tb_user without -fk-pattern = %v", got)
+	}
+
+	inferForeignKeys(tables, "{table}_id")
+	aggregates = resolveAggregates(tables)
+	if got := children(aggregates, "tb_user"); !slices.Equal(got, []string{"tb_user_tag.user_id"}) {
+		t.Errorf("children of tb_user with -fk-pattern = %v", got)
+	}
+	if fks := tables[0].ForeignKeys; len(fks) != 1 || fks[0].ReferencedTable != "tb_user" {
+		t.Errorf("inferred foreign keys of tb_order = %+v", fks)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// repositoryMethod defines a query method derived from an index
+type repositoryMethod struct {
+	name    string
+	kind    string // find, exists, list or listIn
+	columns []string
+}
+
+// repositoryMethodsOf returns the query methods derived from the table indexes:
+// 1. findById / existsById / listByIds for a single column primary key;
+// 2. findByXxx returning an Optional and existsByXxx for every unique index;
+// 3. listByXxx for every prefix of the non-unique indexes.
+func repositoryMethodsOf(table *Table, javaFields []JavaField) []repositoryMethod {
+	fields := make(map[string]JavaField)
+	for _, f := range javaFields {
+		fields[f.Field] = f
+	}
+
+	methods := make([]repositoryMethod, 0)
+	seen := make(map[string]bool)
+	add := func(kind, prefix string, columns []string, suffix string) {
+		names := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if _, ok := fields[fieldNameOf(table.Status.Name, column)]; !ok {
+				return
+			}
+			names = append(names, firstUpCase(fieldNameOf(table.Status.Name, column)))
+		}
+		name := prefix + strings.Join(names, "And") + suffix
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		methods = append(methods, repositoryMethod{name: name, kind: kind, columns: columns})
+	}
+
+	uniqueKeys := make(map[string]bool)
+	for _, index := range table.Indexes {
+		if !index.Unique || len(index.Columns) == 0 {
+			continue
+		}
+		uniqueKeys[strings.ToLower(strings.Join(index.Columns, ","))] = true
+		add("find", "findBy", index.Columns, "")
+		add("exists", "existsBy", index.Columns, "")
+		if index.Primary && len(index.Columns) == 1 {
+			add("listIn", "listBy", index.Columns, "s")
+		}
+	}
+	for _, index := range table.Indexes {
+		if index.Unique {
+			continue
+		}
+		for i := 1; i <= len(index.Columns); i++ {
+			if uniqueKeys[strings.ToLower(strings.Join(index.Columns[:i], ","))] {
+				continue
+			}
+			add("list", "listBy", index.Columns[:i], "")
+		}
+	}
+	return methods
+}
+
+// parseRepositoryMethods returns the imports and codes of the query methods of a repository,
+// see repositoryMethodsOf, querying through the mapper or the Spring Data repository of the table.
+func parseRepositoryMethods(table *Table, javaFields []JavaField, entityClassName, poClassName, factoryClassName, mapperFieldName string) (imports []string, codes string) {
+	methods := repositoryMethodsOf(table, javaFields)
+	if len(methods) == 0 {
+		return nil, ""
+	}
+	imports = repositoryMethodImports(table, javaFields, methods)
+	if target == targetMybatisPlus {
+		imports = append(imports, "com.baomidou.mybatisplus.core.toolkit.Wrappers")
+	}
+
+	var b strings.Builder
+	for _, m := range methods {
+		params, args := repositoryMethodParams(table, javaFields, m)
+		b.WriteString(fmt.Sprintf("\n  public %s %s(%s) {\n", repositoryReturnType(m, entityClassName), m.name, strings.Join(params, ", ")))
+		if m.kind == "listIn" {
+			empty := "Collections.emptyList()"
+			if target == targetR2dbc {
+				empty = "Flux.empty()"
+			}
+			b.WriteString(fmt.Sprintf("    if (CollectionUtils.isEmpty(%s)) {\n      return %s;\n    }\n", args[0], empty))
+		}
+		switch target {
+		case targetJpa, targetJdbc, targetR2dbc:
+			b.WriteString(springDataRepositoryMethodBody(m, args, factoryClassName, mapperFieldName) + "  }\n")
+			continue
+		case targetMybatis:
+			b.WriteString(mybatisRepositoryMethodBody(m, args, factoryClassName, mapperFieldName) + "  }\n")
+			continue
+		}
+		conditions := make([]string, 0, len(m.columns))
+		for i, column := range m.columns {
+			getter := fmt.Sprintf("%s::get%s", poClassName, firstUpCase(fieldNameOf(table.Status.Name, column)))
+			if m.kind == "listIn" {
+				conditions = append(conditions, fmt.Sprintf("        .in(%s, %s)", getter, args[i]))
+				continue
+			}
+			conditions = append(conditions, fmt.Sprintf("        .eq(%s, %s)", getter, args[i]))
+		}
+		b.WriteString(fmt.Sprintf("    var wrapper = Wrappers.<%s>lambdaQuery()\n%s;\n", poClassName, strings.Join(conditions, "\n")))
+		switch m.kind {
+		case "find":
+			b.WriteString(fmt.Sprintf("    return Optional.ofNullable(%s.fromPo(%s.selectOne(wrapper)));\n", factoryClassName, mapperFieldName))
+		case "exists":
+			b.WriteString(fmt.Sprintf("    return %s.selectCount(wrapper) > 0;\n", mapperFieldName))
+		default:
+			b.WriteString(fmt.Sprintf("    return %s.fromPos(%s.selectList(wrapper));\n", factoryClassName, mapperFieldName))
+		}
+		b.WriteString("  }\n")
+	}
+	return imports, b.String()
+}
+
+// repositoryMethodImports returns the imports the signatures of repository methods need.
+func repositoryMethodImports(table *Table, javaFields []JavaField, methods []repositoryMethod) []string {
+	fields := make(map[string]JavaField)
+	for _, f := range javaFields {
+		fields[f.Field] = f
+	}
+	importSet := make(map[string]bool)
+	for _, m := range methods {
+		switch {
+		case target == targetR2dbc && (m.kind == "find" || m.kind == "exists"):
+			importSet["reactor.core.publisher.Mono"] = true
+		case target == targetR2dbc:
+			importSet["reactor.core.publisher.Flux"] = true
+		case m.kind == "find":
+			importSet["java.util.Optional"] = true
+		case m.kind == "list", m.kind == "listIn":
+			importSet["java.util.List"] = true
+		}
+		if m.kind == "listIn" {
+			importSet["java.util.Collection"] = true
+			importSet["org.springframework.util.CollectionUtils"] = true
+			if target != targetR2dbc {
+				importSet["java.util.Collections"] = true
+			}
+		}
+		for _, column := range m.columns {
+			f := fields[fieldNameOf(table.Status.Name, column)]
+			for _, pkg := range append([]string{f.PackageName}, f.Imports...) {
+				if pkg != "" {
+					importSet[pkg] = true
+				}
+			}
+		}
+	}
+	return sortedKeys(importSet)
+}
+
+// repositoryMethodParams returns the parameters of a repository method and their names.
+func repositoryMethodParams(table *Table, javaFields []JavaField, m repositoryMethod) (params, args []string) {
+	for _, column := range m.columns {
+		name := fieldNameOf(table.Status.Name, column)
+		javaType := ""
+		for _, f := range javaFields {
+			if f.Field == name {
+				javaType = f.JavaType
+			}
+		}
+		if m.kind == "listIn" {
+			params = append(params, fmt.Sprintf("Collection<%s> %ss", javaType, name))
+			args = append(args, name+"s")
+			continue
+		}
+		params = append(params, fmt.Sprintf("%s %s", javaType, name))
+		args = append(args, name)
+	}
+	return params, args
+}
+
+// repositoryReturnType returns the result type of a repository method, a Mono or a Flux for the r2dbc target.
+func repositoryReturnType(m repositoryMethod, entityClassName string) string {
+	reactive := target == targetR2dbc
+	switch {
+	case m.kind == "find" && reactive:
+		return fmt.Sprintf("Mono<%s>", entityClassName)
+	case m.kind == "find":
+		return fmt.Sprintf("Optional<%s>", entityClassName)
+	case m.kind == "exists" && reactive:
+		return "Mono<Boolean>"
+	case m.kind == "exists":
+		return "boolean"
+	case reactive:
+		return fmt.Sprintf("Flux<%s>", entityClassName)
+	}
+	return fmt.Sprintf("List<%s>", entityClassName)
+}
+
+// sortJavaImports returns import lines in the order of the generated templates.
+func sortJavaImports(imports []string) string {
+	sorted := append([]string{}, imports...)
+	sort.Strings(sorted)
+	lines := make([]string, 0, len(sorted))
+	for i, v := range sorted {
+		if i > 0 && v == sorted[i-1] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("import %s;", v))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes incompatibly
+const snapshotVersion = 1
+
+// Snapshot defines a versioned dump of introspected tables
+type Snapshot struct {
+	Version int      `json:"version" yaml:"version"`
+	Dialect string   `json:"dialect" yaml:"dialect"`
+	Schema  string   `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Tables  []*Table `json:"tables" yaml:"tables"`
+}
+
+// newSnapshotIntrospector loads a snapshot file, the type mapping follows the dialect it was captured from.
+func newSnapshotIntrospector(file string) (*memoryIntrospector, error) {
+	snapshot, err := readSnapshot(file)
+	if err != nil {
+		return nil, err
+	}
+	dialect = snapshot.Dialect
+	return &memoryIntrospector{source: file, tables: snapshot.Tables}, nil
+}
+
+// inspect dumps the given tables into the snapshot file snapshotOutput.
+func inspect(in Introspector, names []string) error {
+	snapshot := &Snapshot{Version: snapshotVersion, Dialect: dialect, Schema: schemaName, Tables: make([]*Table, 0, len(names))}
+	if ddlFile != "" {
+		snapshot.Dialect, snapshot.Schema = dialectMySQL, ""
+	}
+	if dialect == dialectPostgres {
+		snapshot.Schema = pgSchemaName
+	}
+	for _, name := range names {
+		table, err := readTable(in, name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		snapshot.Tables = append(snapshot.Tables, table)
+	}
+	if err := writeSnapshot(snapshot, snapshotOutput); err != nil {
+		return err
+	}
+	fmt.Printf("%d table(s) written to %s\n", len(snapshot.Tables), snapshotOutput)
+	return nil
+}
+
+// isYAMLFile reports whether a snapshot file is YAML by its extension, everything else is JSON.
+func isYAMLFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func writeSnapshot(snapshot *Snapshot, filename string) error {
+	var buf bytes.Buffer
+	if isYAMLFile(filename) {
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(snapshot); err != nil {
+			return err
+		}
+		_ = encoder.Close()
+	} else {
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(snapshot); err != nil {
+			return err
+		}
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0666)
+}
+
+func readSnapshot(filename string) (*Snapshot, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if isYAMLFile(filename) {
+		err = yaml.Unmarshal(content, snapshot)
+	} else {
+		err = json.Unmarshal(content, snapshot)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > snapshotVersion {
+		return nil, fmt.Errorf("%s: unsupported snapshot version %d, expected %d", filename, snapshot.Version, snapshotVersion)
+	}
+	if snapshot.Dialect == "" {
+		snapshot.Dialect = dialectMySQL
+	}
+	// snapshots written before hasDefault keep non-empty defaults only
+	for _, table := range snapshot.Tables {
+		for i := range table.Columns {
+			table.Columns[i].HasDefault = table.Columns[i].HasDefault || table.Columns[i].Default != ""
+		}
+	}
+	return snapshot, nil
+}
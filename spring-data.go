@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// springDataBases are the Spring Data repositories the repository of the POs extends by target
+var springDataBases = map[string]string{
+	targetJpa:   "org.springframework.data.jpa.repository.JpaRepository",
+	targetJdbc:  "org.springframework.data.repository.CrudRepository",
+	targetR2dbc: "org.springframework.data.repository.reactive.ReactiveCrudRepository",
+}
+
+// parseSpringDataMapperCodes returns the imports and the derived query methods of the Spring Data repository of a
+// table, those the domain repository calls and a finder by every single column foreign key loading aggregate
+// children. The methods the extended repository declares are inherited.
+func parseSpringDataMapperCodes(table *Table, javaFields []JavaField, names templateNames) mapperCodes {
+	fields := make(map[string]JavaField)
+	for _, f := range javaFields {
+		fields[f.Field] = f
+	}
+	methods := repositoryMethodsOf(table, javaFields)
+	for _, fk := range table.ForeignKeys {
+		if len(fk.Columns) != 1 {
+			continue
+		}
+		if f, ok := fields[fieldNameOf(table.Status.Name, fk.Columns[0])]; ok {
+			methods = append(methods, repositoryMethod{name: "listBy" + firstUpCase(f.Field), kind: "list", columns: fk.Columns})
+		}
+	}
+
+	base := springDataBases[target]
+	imports := []string{base, fmt.Sprintf("%s.%s", layerPackages().Po, names.Po)}
+	seen := map[string]bool{"findById": true, "existsById": true, "findAllById": true}
+	var b strings.Builder
+	for _, m := range methods {
+		name := springDataQueryMethodName(m)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		params := make([]string, 0, len(m.columns))
+		for _, column := range m.columns {
+			f := fields[fieldNameOf(table.Status.Name, column)]
+			imports = append(append(imports, f.PackageName), f.Imports...)
+			if m.kind == "listIn" {
+				imports = append(imports, "java.util.Collection")
+				params = append(params, fmt.Sprintf("Collection<%s> %ss", f.JavaType, f.Field))
+				continue
+			}
+			params = append(params, fmt.Sprintf("%s %s", f.JavaType, f.Field))
+		}
+		returnType := repositoryReturnType(m, names.Po)
+		switch {
+		case target == targetR2dbc && (m.kind == "find" || m.kind == "exists"):
+			imports = append(imports, "reactor.core.publisher.Mono")
+		case target == targetR2dbc:
+			imports = append(imports, "reactor.core.publisher.Flux")
+		case m.kind == "find":
+			imports = append(imports, "java.util.Optional")
+		case m.kind != "exists":
+			imports = append(imports, "java.util.List")
+		}
+		b.WriteString(fmt.Sprintf("\n  %s %s(%s);\n", returnType, name, strings.Join(params, ", ")))
+	}
+	idType, idImports := springDataIdType(javaFields, names.Po)
+	imports = slices.DeleteFunc(append(imports, idImports...), func(pkg string) bool { return pkg == "" })
+	return mapperCodes{
+		Imports: sortJavaImports(imports),
+		Base:    simpleClassName(base),
+		IdType:  idType,
+		Methods: b.String(),
+	}
+}
+
+// springDataIdType returns the id type of the Spring Data repository of a table with the imports it needs.
+// Spring Data JDBC and R2DBC map a single column id only, a table without one has a Void id and no findById.
+func springDataIdType(javaFields []JavaField, poClassName string) (string, []string) {
+	if target == targetJpa {
+		return jpaIdType(javaFields, poClassName)
+	}
+	keys := slices.DeleteFunc(slices.Clone(javaFields), func(f JavaField) bool { return !f.IsPri })
+	if len(keys) == 1 {
+		return keys[0].JavaType, append([]string{keys[0].PackageName}, keys[0].Imports...)
+	}
+	return "Void", nil
+}
+
+// springDataQueryMethodName returns the Spring Data repository method a repository method calls,
+// e.g. listByStatus -> findByStatus, listByIds -> findAllById, or findByIdIn for Spring Data JDBC whose
+// CrudRepository returns an Iterable.
+func springDataQueryMethodName(m repositoryMethod) string {
+	switch {
+	case m.kind == "list":
+		return "findBy" + strings.TrimPrefix(m.name, "listBy")
+	case m.kind == "listIn" && target == targetJdbc:
+		return "findBy" + strings.TrimSuffix(strings.TrimPrefix(m.name, "listBy"), "s") + "In"
+	case m.kind == "listIn":
+		return "findAllById"
+	}
+	return m.name
+}
+
+// springDataRepositoryMethodBody returns the statements of a repository method calling the Spring Data repository,
+// mapping the Monos and Fluxes of the r2dbc target.
+func springDataRepositoryMethodBody(m repositoryMethod, args []string, factoryClassName, mapperFieldName string) string {
+	call := fmt.Sprintf("%s.%s(%s)", mapperFieldName, springDataQueryMethodName(m), strings.Join(args, ", "))
+	switch {
+	case m.kind == "find", target == targetR2dbc && m.kind != "exists":
+		return fmt.Sprintf("    return %s.map(%s::fromPo);\n", call, factoryClassName)
+	case m.kind == "exists":
+		return fmt.Sprintf("    return %s;\n", call)
+	}
+	return fmt.Sprintf("    return %s.fromPos(%s);\n", factoryClassName, call)
+}
+
+// parseSpringDataAggregateRepositoryCodes returns the imports, child Spring Data repository members and the methods
+// loading and saving an aggregate root with its children through blocking Spring Data repositories.
+func parseSpringDataAggregateRepositoryCodes(table *Table, pkField, pkType string, children []aggregateChild) (imports []string, fieldCodes, methodCodes string) {
+	names := templateNamesOf(table.Status.Name)
+	pkGetter := "get" + firstUpCase(pkField)
+	packages := layerPackages()
+	imports = []string{
+		fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+		fmt.Sprintf("%s.%s", packages.Factory, names.Factory),
+		"java.util.Optional",
+		"org.springframework.transaction.annotation.Transactional",
+	}
+
+	var fields, load, save strings.Builder
+	loadArgs := []string{"po"}
+	for _, child := range children {
+		childNames := templateNamesOf(child.Table.Status.Name)
+		childColumn := firstUpCase(fieldNameOf(child.Table.Status.Name, child.Column))
+		rootGetter := "get" + firstUpCase(fieldNameOf(table.Status.Name, child.RootColumn))
+		imports = append(imports,
+			fmt.Sprintf("%s.%s", packages.Mapper, childNames.Mapper),
+			fmt.Sprintf("%s.%s", packages.Factory, childNames.Factory),
+		)
+
+		fields.WriteString(fmt.Sprintf("\n  @Resource\n  %s %s %s;\n", repositoryMemberModifier(), childNames.Mapper, childNames.MapperField))
+
+		childPosName := firstLowCase(childNames.Entity) + "Pos"
+		loadArgs = append(loadArgs, childPosName)
+		load.WriteString(fmt.Sprintf("    var %s = %s.findBy%s(po.%s());\n", childPosName, childNames.MapperField, childColumn, rootGetter))
+
+		save.WriteString(fmt.Sprintf("    %s.deleteAll(%s.findBy%s(po.%s()));\n", childNames.MapperField, childNames.MapperField, childColumn, rootGetter))
+		save.WriteString(fmt.Sprintf("    var %s = %s.toPos(aggregate.get%ss());\n", childPosName, childNames.Factory, childNames.Entity))
+		save.WriteString(fmt.Sprintf("    %s.forEach(childPo -> childPo.set%s(po.%s()));\n", childPosName, childColumn, rootGetter))
+		save.WriteString(fmt.Sprintf("    %s.saveAll(%s);\n", childNames.MapperField, childPosName))
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  public Optional<%s> findAggregateBy%s(%s %s) {\n", names.Entity, firstUpCase(pkField), pkType, pkField))
+	b.WriteString(fmt.Sprintf("    var po = %s.findById(%s).orElse(null);\n", names.MapperField, pkField))
+	b.WriteString("    if (po == null) {\n      return Optional.empty();\n    }\n")
+	b.WriteString(load.String())
+	b.WriteString(fmt.Sprintf("    return Optional.of(%s.fromPo(%s));\n  }\n", names.Factory, strings.Join(loadArgs, ", ")))
+
+	b.WriteString("\n  @Transactional(rollbackFor = Exception.class)\n")
+	b.WriteString(fmt.Sprintf("  public %s saveAggregate(%s aggregate) {\n", pkType, names.Entity))
+	b.WriteString(fmt.Sprintf("    var po = %s.save(%s.toPo(aggregate));\n", names.MapperField, names.Factory))
+	b.WriteString(save.String())
+	b.WriteString(fmt.Sprintf("    return po.%s();\n  }\n", pkGetter))
+	return imports, fields.String(), b.String()
+}
+
+// parseRelationalPoCodes returns the imports and members of the Spring Data JDBC or R2DBC entity of a table, every
+// column is declared since the base PO is a MyBatis-Plus class. Only a single column primary key is the @Id, enum
+// columns are mapped by the converters registered as custom conversions.
+func parseRelationalPoCodes(table *Table, javaFields []JavaField) poCodes {
+	single := len(slices.DeleteFunc(slices.Clone(javaFields), func(f JavaField) bool { return !f.IsPri })) == 1
+	imports := []string{"lombok.Data", "org.springframework.data.relational.core.mapping.Column", "org.springframework.data.relational.core.mapping.Table"}
+	fields := slices.Clone(javaFields)
+	for i, col := range table.Columns {
+		f := &fields[i]
+		annotations := make([]string, 0)
+		if f.IsPri && single {
+			annotations = append(annotations, "@Id")
+			imports = append(imports, "org.springframework.data.annotation.Id")
+		}
+		annotations = append(annotations, fmt.Sprintf("@Column(%s)", javaStringLiteral(col.Field)))
+		f.Annotations = annotations
+		imports = append(append(imports, f.PackageName), f.Imports...)
+	}
+
+	codes := poCodes{}
+	_, codes.Fields = parseJavaImportsAndFields(fields)
+	imports = slices.DeleteFunc(imports, func(pkg string) bool { return pkg == "" })
+	codes.Imports = sortJavaImports(imports)
+	return codes
+}
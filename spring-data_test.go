@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpringDataRepositoryIdType(t *testing.T) {
+	defer func(t string) { target = t }(target)
+	table := parseTable(t, "CREATE TABLE tb_user (id bigint unsigned NOT NULL AUTO_INCREMENT PRIMARY KEY, name varchar(32), KEY idx_name (name))")
+	tests := []struct {
+		target string
+		file   string
+		want   []string
+	}{
+		{targetJpa, "user/infrastructure/persistence/mapper/UserJpaRepository.java", []string{
+			"import java.math.BigInteger;",
+			"extends JpaRepository<UserPo, BigInteger>",
+			"List<UserPo> findByName(String name);",
+		}},
+		{targetJdbc, "user/infrastructure/persistence/mapper/UserJdbcRepository.java", []string{
+			"import java.math.BigInteger;",
+			"extends CrudRepository<UserPo, BigInteger>",
+			"List<UserPo> findByIdIn(Collection<BigInteger> ids);",
+		}},
+		{targetR2dbc, "user/infrastructure/persistence/mapper/UserR2dbcRepository.java", []string{
+			"import java.math.BigInteger;",
+			"extends ReactiveCrudRepository<UserPo, BigInteger>",
+			"Flux<UserPo> findByName(String name);",
+		}},
+	}
+	for _, tt := range tests {
+		target = tt.target
+		codes, ok := renderTable(t, table)[tt.file]
+		if !ok {
+			t.Errorf("%s was not generated", tt.file)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(codes, want) {
+				t.Errorf("%s does not contain %q:\n%s", tt.file, want, codes)
+			}
+		}
+	}
+}
+
+func TestRelationalPoCodes(t *testing.T) {
+	defer func(t, d string) { target, dialect = t, d }(target, dialect)
+	target, dialect = targetJdbc, dialectMySQL
+	files := renderTable(t, parseTable(t, "CREATE TABLE tb_user_role (user_id bigint unsigned NOT NULL, role_id int NOT NULL, PRIMARY KEY (user_id, role_id))"))
+	po := files["user/infrastructure/persistence/po/UserRolePo.java"]
+	if strings.Contains(po, "@Id") || !strings.Contains(po, `@Column("user_id")`) || !strings.Contains(po, "import java.math.BigInteger;") {
+		t.Errorf("UserRolePo.java maps a composite key to @Id or misses a column:\n%s", po)
+	}
+	if repository := files["user/infrastructure/persistence/mapper/UserRoleJdbcRepository.java"]; !strings.Contains(repository, "extends CrudRepository<UserRolePo, Void>") {
+		t.Errorf("UserRoleJdbcRepository.java does not use a Void id:\n%s", repository)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// tablePattern matches table names, it is one of:
+// 1. an exact name, tb_user;
+// 2. a glob pattern, tb_order*;
+// 3. a regular expression between slashes, /^tb_(order|item)$/.
+type tablePattern struct {
+	raw   string
+	exact bool
+	match func(string) bool
+}
+
+// tableFilter selects tables by include and exclude patterns
+type tableFilter struct {
+	includes []tablePattern
+	excludes []tablePattern
+}
+
+// newTableFilter builds a filter from comma separated pattern lists, an empty include list matches every table.
+func newTableFilter(include, exclude string) (*tableFilter, error) {
+	includes, err := parseTablePatterns(include)
+	if err != nil {
+		return nil, err
+	}
+	excludes, err := parseTablePatterns(exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &tableFilter{includes: includes, excludes: excludes}, nil
+}
+
+// parseTablePatterns splits a comma separated list into table patterns.
+func parseTablePatterns(s string) ([]tablePattern, error) {
+	patterns := make([]tablePattern, 0)
+	for _, raw := range strings.Split(s, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		p, err := parseTablePattern(raw)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// parseTablePattern parses a single pattern, a regular expression may hold commas, e.g. /^c_\d{1,3}$/.
+func parseTablePattern(raw string) (tablePattern, error) {
+	if len(raw) > 2 && strings.HasPrefix(raw, "/") && strings.HasSuffix(raw, "/") {
+		re, err := regexp.Compile(raw[1 : len(raw)-1])
+		if err != nil {
+			return tablePattern{}, fmt.Errorf("invalid table pattern %s: %w", raw, err)
+		}
+		return tablePattern{raw: raw, match: re.MatchString}, nil
+	}
+	if strings.ContainsAny(raw, "*?[") {
+		if _, err := path.Match(raw, ""); err != nil {
+			return tablePattern{}, fmt.Errorf("invalid table pattern %s: %w", raw, err)
+		}
+		return tablePattern{raw: raw, match: func(name string) bool {
+			ok, _ := path.Match(raw, name)
+			return ok
+		}}, nil
+	}
+	return tablePattern{raw: raw, exact: true, match: func(s string) bool { return s == raw }}, nil
+}
+
+// filter returns the matched tables in the given order, and the exact names which matched nothing.
+func (f *tableFilter) filter(tables []string) (matched, missing []string) {
+	matched = make([]string, 0)
+	hits := make(map[string]bool)
+	for _, table := range tables {
+		if !f.included(table, hits) || f.excluded(table) {
+			continue
+		}
+		matched = append(matched, table)
+	}
+	missing = make([]string, 0)
+	for _, p := range f.includes {
+		if p.exact && !hits[p.raw] {
+			missing = append(missing, p.raw)
+		}
+	}
+	return
+}
+
+func (f *tableFilter) included(table string, hits map[string]bool) bool {
+	if len(f.includes) == 0 {
+		return true
+	}
+	ok := false
+	for _, p := range f.includes {
+		if p.match(table) {
+			hits[p.raw] = true
+			ok = true
+		}
+	}
+	return ok
+}
+
+func (f *tableFilter) excluded(table string) bool {
+	for _, p := range f.excludes {
+		if p.match(table) {
+			return true
+		}
+	}
+	return false
+}
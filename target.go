@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	targetMybatisPlus = "mybatis-plus" // the default templates
+	targetMybatis     = "mybatis"      // plain MyBatis with XML mappers
+	targetJpa         = "jpa"
+	targetJdbc        = "jdbc"  // Spring Data JDBC
+	targetR2dbc       = "r2dbc" // Spring Data R2DBC with reactive repositories
+)
+
+// targets are the persistence frameworks the generated code can target
+var targets = []string{targetMybatisPlus, targetMybatis, targetJpa, targetJdbc, targetR2dbc}
+
+// targetPacks are the packs of templates under templates/<pack> replacing the default templates of the same name,
+// jdbc and r2dbc share the Spring Data Relational one
+var targetPacks = map[string]string{
+	targetMybatis: "mybatis",
+	targetJpa:     "jpa",
+	targetJdbc:    "relational",
+	targetR2dbc:   "relational",
+}
+
+// target is the persistence framework of the generated code, see -target
+var target = targetMybatisPlus
+
+// validateTarget checks a target name.
+func validateTarget(name string) error {
+	if !slices.Contains(targets, name) {
+		return fmt.Errorf("unknown target %s, expected one of %s", name, strings.Join(targets, ", "))
+	}
+	return nil
+}
+
+// targetTemplateDir returns the directory of the embedded templates of a target replacing the default ones,
+// empty for the default target.
+func targetTemplateDir(name string) string {
+	pack, ok := targetPacks[name]
+	if !ok {
+		return ""
+	}
+	return "templates/" + pack
+}
+
+// springDataTarget reports whether the generated code queries through Spring Data repositories.
+func springDataTarget() bool {
+	return target == targetJpa || target == targetJdbc || target == targetR2dbc
+}
@@ -0,0 +1,514 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl templates/*/*.tmpl
+var builtinTemplates embed.FS
+
+const (
+	templateScopeTable = "table" // rendered once per table, the default
+	templateScopeEnum  = "enum"  // rendered once per enum of a table, with templateData.Enum set
+)
+
+// artifactTemplate defines a template generating one file per table or per enum.
+// Besides its content a template defines:
+// 1. "path", the output file relative to -out or the source root of -project, nothing is written if it renders empty;
+// 2. "scope", optional, table or enum;
+// 3. "write", optional, always or once for a file created when missing and left to the developers afterwards.
+// Templates whose name starts with _ hold partials shared by every template, e.g. "javadoc".
+type artifactTemplate struct {
+	name  string
+	scope string
+	once  bool
+	tmpl  *template.Template
+}
+
+// templateNames holds the class and member names derived from a table
+type templateNames struct {
+	Entity          string
+	EntityBase      string // the abstract entity in generation gap mode
+	Po              string
+	Mapper          string
+	MapperField     string
+	Repository      string
+	RepositoryField string
+	RepositoryBase  string // the abstract repository in generation gap mode
+	Factory         string
+	AppService      string
+	AppServiceBase  string // the abstract app service in generation gap mode
+	Assembler       string
+	Command         string // the command creating an entity, see -validation
+}
+
+// templateUtils holds the util classes, fully qualified and by simple name
+type templateUtils struct {
+	BasePo       string
+	BasePoName   string
+	BeanCopy     string
+	BeanCopyName string
+}
+
+// poCodes holds the code fragments of a PO
+type poCodes struct {
+	Imports       string
+	Fields        string
+	AutoResultMap bool   // a member is mapped through a type handler
+	Key           string // members of the id class of a composite key, jpa target
+}
+
+// mapperCodes holds the code fragments of a mapper, the Spring Data repository of the jpa, jdbc and r2dbc targets
+type mapperCodes struct {
+	Imports string
+	Base    string // the Spring Data repository extended, e.g. JpaRepository
+	IdType  string // Spring Data targets
+	Methods string
+	// the XML mapper of the mybatis target
+	Table         string // quoted table name
+	Columns       []mapperColumn
+	InsertColumns []mapperColumn // columns but the auto increment and generated ones
+	UpdateColumns []mapperColumn // insert columns but the key
+	KeyColumns    []mapperColumn
+	GeneratedKey  string // property of the auto increment key
+	KeyParams     string // Java parameters of the key, e.g. @Param("id") Long id
+	Statements    string
+}
+
+// entityCodes holds the code fragments of an entity
+type entityCodes struct {
+	Imports string
+	Fields  string
+}
+
+// commandCodes holds the code fragments of the command creating an entity
+type commandCodes struct {
+	Imports string
+	Fields  string
+}
+
+// repositoryCodes holds the code fragments of a repository
+type repositoryCodes struct {
+	Imports string
+	Fields  string // members besides the mapper of the table, e.g. the mappers of aggregate children
+	Methods string
+}
+
+// serviceCodes holds the code fragments of an app service
+type serviceCodes struct {
+	Imports string
+	Methods string // reactive delegates of the r2dbc target
+}
+
+// factoryCodes holds the code fragments of a factory
+type factoryCodes struct {
+	Imports          string
+	AggregateMethods string
+	EnumMethods      string
+	// the constructor arguments and assignments of the Kotlin converters
+	EntityArgs    string
+	PoArgs        string
+	PoAssignments string
+}
+
+// templateData is the data model every template is executed with
+type templateData struct {
+	Domain     string
+	Now        string
+	Gap        bool   // generation gap mode, see -generation-gap
+	Target     string // persistence framework, see -target
+	Lang       string // language of the generated code, see -lang
+	Validation string // namespace of the Bean Validation constraints, see -validation
+	Namespace  string // javax or jakarta, namespace of the persistence and injection annotations
+	Table      *Table
+	Fields     []JavaField // members of every column, typed as their enums
+	Enums      []*javaEnum
+	Enum       *javaEnum // the enum an enum scoped template renders
+	Children   []aggregateChild
+	Names      templateNames
+	Packages   LayerPackages // fully qualified package of every layer
+	Utils      templateUtils
+	Po         poCodes
+	Mapper     mapperCodes
+	Entity     entityCodes
+	Repository repositoryCodes
+	Service    serviceCodes
+	Factory    factoryCodes
+	Command    commandCodes
+}
+
+// javadocData is the data of the javadoc partial
+type javadocData struct {
+	ClassName string
+	Comment   string
+	TableName string
+	Now       string
+}
+
+// Doc returns the javadoc data of a class generated from the table.
+func (d *templateData) Doc(className, comment string) javadocData {
+	return javadocData{ClassName: className, Comment: comment, TableName: d.Table.Status.Name, Now: d.Now}
+}
+
+// templateFuncs are the naming helpers available to templates
+var templateFuncs = template.FuncMap{
+	"camelCase":       camelCase,
+	"pascalCase":      func(s string) string { return firstUpCase(camelCase(s)) },
+	"firstUpCase":     firstUpCase,
+	"firstLowCase":    firstLowCase,
+	"trimTablePrefix": tryRemoveTablePrefix,
+	"entityName":      entityClassNameOf,
+	"javaString":      javaStringLiteral,
+	"kotlinString":    kotlinStringLiteral,
+	"kotlinType":      kotlinType,
+	"packageDir":      func(pkg string) string { return layout.packageDir(pkg) },
+	"imports":         sortJavaImports,
+	"fieldImports":    fieldImportCodes,
+	"lower":           strings.ToLower,
+	"upper":           strings.ToUpper,
+	"join":            strings.Join,
+	"replace":         strings.ReplaceAll,
+	"hasPrefix":       strings.HasPrefix,
+	"hasSuffix":       strings.HasSuffix,
+}
+
+// loadTemplates returns the built-in templates, overridden or extended by the *.tmpl files of dir.
+func loadTemplates(dir string) ([]*artifactTemplate, error) {
+	sources := make(map[string]string)
+	if err := readTemplateSources(builtinTemplates, "templates", sources); err != nil {
+		return nil, err
+	}
+	if pack := targetTemplateDir(target); pack != "" {
+		if err := readTemplateSources(builtinTemplates, pack, sources); err != nil {
+			return nil, err
+		}
+	}
+	if lang == langKotlin {
+		for _, name := range builtinTemplateOrder() {
+			if strings.HasSuffix(name, ".java.tmpl") {
+				delete(sources, name)
+			}
+		}
+		if err := readTemplateSources(builtinTemplates, "templates/kotlin", sources); err != nil {
+			return nil, err
+		}
+	}
+	if dir != "" {
+		if err := readTemplateSources(os.DirFS(dir), ".", sources); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0)
+	partials := make([]string, 0)
+	for _, name := range sortedKeys(sources) {
+		if strings.HasPrefix(name, "_") {
+			partials = append(partials, name)
+		} else if !slices.Contains(builtinTemplateOrder(), name) {
+			names = append(names, name)
+		}
+	}
+	names = append(builtinTemplateOrder(), names...)
+
+	templates := make([]*artifactTemplate, 0, len(names))
+	for _, name := range names {
+		// a built-in template only some targets provide
+		if _, ok := sources[name]; !ok {
+			continue
+		}
+		tmpl := template.New(name).Funcs(templateFuncs)
+		for _, partial := range partials {
+			if _, err := tmpl.New(partial).Parse(sources[partial]); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := tmpl.Parse(sources[name]); err != nil {
+			return nil, err
+		}
+		if tmpl.Lookup("path") == nil {
+			return nil, fmt.Errorf("template %s: missing {{define \"path\"}}", name)
+		}
+		t := &artifactTemplate{name: name, scope: templateScopeTable, tmpl: tmpl}
+		if tmpl.Lookup("scope") != nil {
+			var scope bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&scope, "scope", nil); err != nil {
+				return nil, err
+			}
+			t.scope = strings.TrimSpace(scope.String())
+		}
+		if t.scope != templateScopeTable && t.scope != templateScopeEnum {
+			return nil, fmt.Errorf("template %s: unknown scope %s", name, t.scope)
+		}
+		if tmpl.Lookup("write") != nil {
+			var write bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&write, "write", nil); err != nil {
+				return nil, err
+			}
+			switch strings.TrimSpace(write.String()) {
+			case "always":
+			case "once":
+				t.once = true
+			default:
+				return nil, fmt.Errorf("template %s: unknown write %s", name, strings.TrimSpace(write.String()))
+			}
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// readTemplateSources reads the *.tmpl files of a directory into sources by file name.
+func readTemplateSources(fsys fs.FS, dir string, sources map[string]string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			return err
+		}
+		sources[entry.Name()] = string(content)
+	}
+	return nil
+}
+
+// render executes every template against a table and plans the generated files against those on disk.
+func render(templates []*artifactTemplate, data *templateData) ([]*plannedFile, error) {
+	files := make([]*plannedFile, 0, len(templates))
+	for _, t := range templates {
+		scoped := []*templateData{data}
+		if t.scope == templateScopeEnum {
+			scoped = make([]*templateData, 0, len(data.Enums))
+			for _, e := range data.Enums {
+				d := *data
+				d.Enum = e
+				scoped = append(scoped, &d)
+			}
+		}
+		for _, d := range scoped {
+			var path, codes bytes.Buffer
+			if err := t.tmpl.ExecuteTemplate(&path, "path", d); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(path.String()) == "" {
+				continue
+			}
+			if err := t.tmpl.Execute(&codes, d); err != nil {
+				return nil, err
+			}
+			filename := outputPath(strings.TrimSpace(path.String()))
+			plan := planGenerated
+			if t.once {
+				plan = planOnce
+			}
+			f, err := plan(filename, codes.String())
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// templateNamesOf returns the class and member names derived from a table,
+// the mapper of a Spring Data target is a repository of the POs, e.g. UserJpaRepository.
+func templateNamesOf(tableName string) templateNames {
+	entityClassName := entityClassNameOf(tableName)
+	mapper := "Mapper"
+	switch target {
+	case targetJpa:
+		mapper = "JpaRepository"
+	case targetJdbc:
+		mapper = "JdbcRepository"
+	case targetR2dbc:
+		mapper = "R2dbcRepository"
+	}
+	return templateNames{
+		Entity:          entityClassName,
+		EntityBase:      entityClassName + "Base",
+		Po:              entityClassName + "Po",
+		Mapper:          entityClassName + mapper,
+		MapperField:     firstLowCase(entityClassName) + mapper,
+		Repository:      entityClassName + "Repository",
+		RepositoryField: firstLowCase(entityClassName) + "Repository",
+		RepositoryBase:  "Abstract" + entityClassName + "Repository",
+		Factory:         entityClassName + "Factory",
+		AppService:      entityClassName + "AppService",
+		AppServiceBase:  "Abstract" + entityClassName + "AppService",
+		Assembler:       entityClassName + "Assembler",
+		Command:         "Create" + entityClassName + "Command",
+	}
+}
+
+// newTemplateData builds the data model of a table, children are the tables composed into it as an aggregate root.
+func newTemplateData(table *Table, children []aggregateChild) *templateData {
+	d := &templateData{
+		Domain:     domainName,
+		Now:        time.Now().String(),
+		Gap:        generationGap,
+		Target:     target,
+		Lang:       lang,
+		Validation: validation,
+		Namespace:  eeNamespace(),
+		Table:      table,
+		Children:   children,
+		Packages:   layerPackages(),
+		Utils: templateUtils{
+			BasePo:       layout.Utils.BasePo,
+			BasePoName:   simpleClassName(layout.Utils.BasePo),
+			BeanCopy:     layout.Utils.BeanCopy,
+			BeanCopyName: simpleClassName(layout.Utils.BeanCopy),
+		},
+		Names: templateNamesOf(table.Status.Name),
+	}
+
+	// parse class members
+	d.Fields = parseJavaFields(table)
+	d.Enums = parseJavaEnums(table, d.Fields)
+	parseValidationConstraints(table, d.Fields)
+	d.Command = parseCommandCodes(table, d.Fields)
+
+	switch target {
+	case targetJpa:
+		d.Po = parseJpaPoCodes(table, d.Fields, d.Enums)
+		d.Mapper = parseSpringDataMapperCodes(table, d.Fields, d.Names)
+	case targetJdbc, targetR2dbc:
+		d.Po = parseRelationalPoCodes(table, d.Fields)
+		d.Mapper = parseSpringDataMapperCodes(table, d.Fields, d.Names)
+	case targetMybatis:
+		d.Po.Imports, d.Po.Fields = parseJavaImportsAndFields(d.Fields, basePoFields()...)
+		d.Mapper = parseMybatisMapperCodes(table, d.Fields, d.Enums, d.Names)
+	default:
+		poFields, autoResultMap := poJavaFields(d.Fields, d.Enums)
+		d.Po.Imports, d.Po.Fields = parseJavaImportsAndFields(poFields, basePoFields()...)
+		d.Po.AutoResultMap = autoResultMap
+	}
+
+	if lang == langKotlin {
+		d.Po = parseKotlinPoCodes(d.Fields, d.Enums)
+		d.Entity = parseKotlinEntityCodes(table, d.Fields, children)
+		d.Repository = parseKotlinRepositoryCodes(table, d.Fields, children, d.Names)
+		d.Factory = parseKotlinFactoryCodes(table, d.Fields, children, d.Enums, d.Names)
+		return d
+	}
+
+	d.Entity.Imports, d.Entity.Fields = parseEntityImportsAndFields(table, d.Fields, children)
+	d.Repository = parseRepositoryCodes(table, d.Fields, children, d.Names)
+	d.Service = parseServiceCodes(table, d.Fields, children, d.Names)
+	d.Factory = parseFactoryCodes(children, d.Enums, d.Names)
+	return d
+}
+
+// parseEntityImportsAndFields returns the members of an entity, an aggregate root keeps its identity
+// to be saved with its children.
+func parseEntityImportsAndFields(table *Table, javaFields []JavaField, children []aggregateChild) (importCodes, fieldCodes string) {
+	skipped := basePoFields()
+	if len(children) > 0 {
+		pk := fieldNameOf(table.Status.Name, primaryKeyColumn(table))
+		skipped = slices.DeleteFunc(slices.Clone(skipped), func(f string) bool { return f == pk })
+	}
+	javaFields = validatedJavaFields(javaFields, skipped...)
+	if len(children) > 0 {
+		javaFields = append(javaFields, parseAggregateEntityFields(children)...)
+	}
+	importCodes, fieldCodes = parseJavaImportsAndFields(javaFields, skipped...)
+	// the constraint imports are merged in order
+	if validation != "" && importCodes != "" {
+		imports := make([]string, 0)
+		for _, line := range strings.Split(importCodes, "\n") {
+			imports = append(imports, strings.TrimSuffix(strings.TrimPrefix(line, "import "), ";"))
+		}
+		importCodes = sortJavaImports(imports)
+	}
+	return importCodes, fieldCodes
+}
+
+// parseServiceCodes returns the imports and methods of an app service.
+func parseServiceCodes(table *Table, javaFields []JavaField, children []aggregateChild, names templateNames) serviceCodes {
+	imports := []string{
+		fmt.Sprintf("%s.%s", layerPackages().Repository, names.Repository),
+		eeNamespace() + ".annotation.Resource",
+	}
+	// the concrete service carries the annotation in generation gap mode
+	if !generationGap {
+		imports = append(imports, "org.springframework.stereotype.Service")
+	}
+	codes := serviceCodes{}
+	if target == targetR2dbc {
+		var methodImports []string
+		methodImports, codes.Methods = parseReactiveServiceCodes(table, javaFields, children, names)
+		imports = append(imports, methodImports...)
+	}
+	codes.Imports = sortJavaImports(imports)
+	return codes
+}
+
+// parseRepositoryCodes returns the imports, members and query methods of a repository.
+func parseRepositoryCodes(table *Table, javaFields []JavaField, children []aggregateChild, names templateNames) repositoryCodes {
+	methodImports, methodCodes := parseRepositoryMethods(table, javaFields, names.Entity, names.Po, names.Factory, names.MapperField)
+	aggregateImports, aggregateFieldCodes, aggregateMethodCodes := parseAggregateRepositoryCodes(table, javaFields, children)
+	methodCodes += aggregateMethodCodes
+
+	packages := layerPackages()
+	imports := []string{
+		fmt.Sprintf("%s.%s", packages.Mapper, names.Mapper),
+		eeNamespace() + ".annotation.Resource",
+	}
+	// the concrete repository carries the annotations in generation gap mode
+	if !generationGap {
+		imports = append(imports, "lombok.extern.slf4j.Slf4j", "org.springframework.stereotype.Repository")
+	}
+	if methodCodes != "" {
+		imports = append(imports,
+			fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+			fmt.Sprintf("%s.%s", packages.Factory, names.Factory),
+		)
+		// only the MyBatis-Plus queries name the PO
+		if target == targetMybatisPlus {
+			imports = append(imports, fmt.Sprintf("%s.%s", packages.Po, names.Po))
+		}
+		imports = append(imports, methodImports...)
+		imports = append(imports, aggregateImports...)
+	}
+	return repositoryCodes{Imports: sortJavaImports(imports), Fields: aggregateFieldCodes, Methods: methodCodes}
+}
+
+// parseFactoryCodes returns the imports and the methods a factory needs besides the PO conversions.
+func parseFactoryCodes(children []aggregateChild, enums []*javaEnum, names templateNames) factoryCodes {
+	packages := layerPackages()
+	imports := []string{
+		fmt.Sprintf("%s.%s", packages.Entity, names.Entity),
+		fmt.Sprintf("%s.%s", packages.Po, names.Po),
+		layout.Utils.BeanCopy,
+		"java.util.Collections",
+		"java.util.List",
+		"java.util.Objects",
+		"java.util.stream.Collectors",
+		"lombok.extern.slf4j.Slf4j",
+		"org.springframework.util.CollectionUtils",
+	}
+	codes := factoryCodes{}
+	if len(children) > 0 {
+		var aggregateImports []string
+		aggregateImports, codes.AggregateMethods = parseAggregateFactoryCodes(children, names.Entity, names.Po)
+		imports = append(imports, aggregateImports...)
+	}
+	enumImports, enumCodes := parseEnumFactoryCodes(enums)
+	codes.EnumMethods = enumCodes
+	codes.Imports = sortJavaImports(append(imports, enumImports...))
+	return codes
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// renderTable renders the built-in templates of the current target against a table of the user domain in a
+// temporary output directory, the generated files are returned by their path relative to it.
+func renderTable(t *testing.T, table *Table) map[string]string {
+	t.Helper()
+	defer func(d, o string, m *typeMapper) { domainName, outputDir, javaTypeMapper = d, o, m }(domainName, outputDir, javaTypeMapper)
+	domainName, outputDir = "user", t.TempDir()
+	var err error
+	if javaTypeMapper, err = newTypeMapper(dialect, nil); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := loadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := render(templates, newTemplateData(table, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes := make(map[string]string, len(files))
+	for _, f := range files {
+		rel, err := filepath.Rel(outputDir, f.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		codes[filepath.ToSlash(rel)] = f.codes
+	}
+	return codes
+}
+
+// parseTable parses a single CREATE TABLE statement.
+func parseTable(t *testing.T, ddl string) *Table {
+	t.Helper()
+	tables, err := parseDDL(ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("parseDDL returned %d tables, want 1", len(tables))
+	}
+	return tables[0]
+}
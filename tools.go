@@ -1,111 +1,43 @@
 package main
 
 import (
-	"fmt"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
+	"flag"
 	"os"
+	"sort"
 	"strings"
 )
 
-const (
-	sqlShowTableStatus = "SHOW TABLE STATUS LIKE '%s'"
-	sqlShowFullColumns = "SHOW FULL COLUMNS FROM %s"
-)
-
-// connectToDB inits mDB to connect to the Database.
-func connectToDB() {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", username, password, host, port, schemaName)
-	fmt.Printf("dsn: %s\n\n", dsn)
-	if mDB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{}); err != nil {
-		panic(err)
-	}
-}
-
-// readTablePrototype returns table status and columns by fetching MySQL.
-func readTablePrototype() (*TableStatus, []ColumnsStatement) {
-	tableStatus := &TableStatus{}
-	if err = mDB.Raw(fmt.Sprintf(sqlShowTableStatus, tableName)).First(&tableStatus).Error; err != nil {
-		panic(err)
-	}
-	columnsStatements := make([]ColumnsStatement, 0)
-	if err = mDB.Raw(fmt.Sprintf(sqlShowFullColumns, tableName)).Find(&columnsStatements).Error; err != nil {
-		panic(err)
-	}
-	return tableStatus, columnsStatements
-}
-
 // parseJavaFields returns Java fields by analysing table info
-func parseJavaFields(columns []ColumnsStatement) (javaFields []JavaField) {
+func parseJavaFields(table *Table) (javaFields []JavaField) {
 	javaFields = make([]JavaField, 0)
-	for _, v := range columns {
-		javaType, packageName := getStructType(v)
+	for _, v := range table.Columns {
+		javaType, packageName := javaTypeMapper.javaType(table.Status.Name, v)
 		f := JavaField{
 			JavaType:    javaType,
-			Field:       camelCase(v.Field),
+			Field:       fieldNameOf(table.Status.Name, v.Field),
+			Column:      v.Field,
 			Comment:     v.Comment,
 			PackageName: packageName,
 			IsPri:       strings.ToUpper(v.Key) == "PRI",
+			Nullable:    strings.EqualFold(v.Null, "YES") || strings.Contains(strings.ToLower(v.Extra), "auto_increment"),
 		}
 		javaFields = append(javaFields, f)
 	}
 	return
 }
 
-// getStructType returns Java type by DB type
-func getStructType(s ColumnsStatement) (string, string) {
-	types := strings.ToLower(s.Type)
-	if strings.Contains(types, "varchar") ||
-		strings.Contains(types, "char") ||
-		strings.Contains(types, "text") ||
-		strings.Contains(types, "json") {
-		return "String", ""
-	}
-	if strings.Contains(types, "bigint") {
-		return "Long", ""
-	}
-	if strings.Contains(types, "tinyint") ||
-		strings.Contains(types, "integer") ||
-		strings.Contains(types, "int") {
-		return "Integer", ""
-	}
-	if strings.Contains(types, "decimal") ||
-		strings.Contains(types, "numeric") ||
-		strings.Contains(types, "double") ||
-		strings.Contains(types, "float") ||
-		strings.Contains(types, "real") {
-		return "BigDecimal", "java.math.BigDecimal"
-	}
-	if strings.Contains(types, "bit") ||
-		strings.Contains(types, "boolean") {
-		return "Boolean", ""
-	}
-	if strings.Contains(types, "binary") {
-		return "byte[]", ""
-	}
-	if strings.Contains(types, "date") ||
-		strings.Contains(types, "time") {
-		return "LocalDateTime", "java.time.LocalDateTime"
-	}
-	return "String", ""
-}
+// tablePrefixes are stripped from table names, naming.tablePrefixes of the configuration file replaces them
+var tablePrefixes = []string{"tb_", "t_", "r_"}
 
-// tryRemoveTablePrefix will remove table prefix by the following rules:
+// tryRemoveTablePrefix will remove the first of tablePrefixes a table name starts with, by default:
 // 1. tb_table -> table;
 // 2. t_table -> table;
 // 3. r_relation -> relation.
 func tryRemoveTablePrefix(s string) string {
-	if len(s) == 0 {
-		return s
-	}
-	if strings.HasPrefix(s, "tb_") {
-		return strings.TrimPrefix(s, "tb_")
-	}
-	if strings.HasPrefix(s, "t_") {
-		return strings.TrimPrefix(s, "t_")
-	}
-	if strings.HasPrefix(s, "r_") {
-		return strings.TrimPrefix(s, "r_")
+	for _, prefix := range tablePrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return strings.TrimPrefix(s, prefix)
+		}
 	}
 	return s
 }
@@ -147,11 +79,41 @@ func firstUpCase(str string) string {
 	return string(b)
 }
 
-func writeFile(path, filename, codes string) {
-	if _, err = os.Stat(path); os.IsNotExist(err) {
-		if err = os.MkdirAll(path, os.ModePerm); err != nil {
-			panic(err)
+// firstLowCase makes the first character to lower case.
+func firstLowCase(str string) string {
+	if len(str) == 0 || !('A' <= str[0] && str[0] <= 'Z') {
+		return str
+	}
+	b := []byte(str)
+	b[0] += 'a' - 'A'
+	return string(b)
+}
+
+// isFlagPassed reports whether a flag was set on the command line.
+func isFlagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
 		}
+	})
+	return passed
+}
+
+// sortedKeys returns the keys of a map in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeFile writes codes into filename, creating its directory path when missing.
+func writeFile(path, filename, codes string) error {
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return err
 	}
-	_ = os.WriteFile(filename, []byte(codes), 0666)
+	return os.WriteFile(filename, []byte(codes), 0666)
 }
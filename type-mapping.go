@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"strings"
+)
+
+// sqlType defines a parsed column type, e.g. bigint(20) unsigned
+type sqlType struct {
+	name     string   // lower case bare type name, e.g. bigint or double precision
+	args     []string // arguments between the parentheses, e.g. 10 and 2 of decimal(10,2)
+	unsigned bool
+}
+
+// parseSQLType parses a column type string, modifiers like unsigned or zerofill are stripped from the name
+// and the words following the arguments are kept, e.g. timestamp(3) with time zone -> timestamp with time zone.
+func parseSQLType(s string) sqlType {
+	t := sqlType{args: make([]string, 0)}
+	words := make([]string, 0)
+	var arg strings.Builder
+	depth, quoted := 0, false
+	word := func(w string) {
+		for _, f := range strings.Fields(w) {
+			switch f {
+			case "unsigned":
+				t.unsigned = true
+			case "signed", "zerofill":
+			default:
+				words = append(words, f)
+			}
+		}
+	}
+	start := 0
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quoted:
+			arg.WriteByte(c)
+			if c == '\'' {
+				quoted = false
+			}
+		case c == '\'' && depth > 0:
+			quoted = true
+			arg.WriteByte(c)
+		case c == '(':
+			if depth == 0 {
+				word(s[start:i])
+				arg.Reset()
+			} else {
+				arg.WriteByte(c)
+			}
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+			if depth == 0 {
+				t.args = append(t.args, strings.TrimSpace(arg.String()))
+				start = i + 1
+			} else {
+				arg.WriteByte(c)
+			}
+		case c == ',' && depth == 1:
+			t.args = append(t.args, strings.TrimSpace(arg.String()))
+			arg.Reset()
+		case depth > 0:
+			arg.WriteByte(c)
+		}
+	}
+	if depth == 0 {
+		word(s[start:])
+	}
+	if len(t.args) == 1 && t.args[0] == "" {
+		t.args = t.args[:0]
+	}
+	t.name = strings.Join(words, " ")
+	return t
+}
+
+// columnSQLType returns the parsed type of a column, the bare data type names it when introspected,
+// e.g. the udt name int4 of a PostgreSQL integer column.
+func columnSQLType(col ColumnsStatement) sqlType {
+	t := parseSQLType(col.Type)
+	if col.DataType != "" {
+		t.name = parseSQLType(col.DataType).name
+	}
+	t.unsigned = t.unsigned || col.Unsigned
+	return t
+}
+
+// typeRule maps the SQL types matching a type pattern to a Java type
+type typeRule struct {
+	pattern  sqlType
+	javaType string
+}
+
+// matches returns the specificity of a match, the more arguments a pattern names the more specific it is,
+// -1 if the type does not match.
+func (r typeRule) matches(t sqlType) int {
+	if r.pattern.name != t.name || len(r.pattern.args) > len(t.args) || (r.pattern.unsigned && !t.unsigned) {
+		return -1
+	}
+	for i, arg := range r.pattern.args {
+		if arg != t.args[i] {
+			return -1
+		}
+	}
+	specificity := len(r.pattern.args)
+	if r.pattern.unsigned {
+		specificity++
+	}
+	return specificity
+}
+
+// newTypeRules parses pattern and Java type pairs into rules.
+func newTypeRules(pairs ...string) []typeRule {
+	rules := make([]typeRule, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		rules = append(rules, typeRule{pattern: parseSQLType(pairs[i]), javaType: pairs[i+1]})
+	}
+	return rules
+}
+
+// mysqlTypeRules are the default rules of MySQL, also the fallback of SQLite
+var mysqlTypeRules = newTypeRules(
+	"tinyint(1)", "Boolean",
+	"bool", "Boolean",
+	"boolean", "Boolean",
+	"bit(1)", "Boolean",
+	"bit", "byte[]",
+	"tinyint", "Integer",
+	"smallint", "Integer",
+	"mediumint", "Integer",
+	"int", "Integer",
+	"integer", "Integer",
+	"int unsigned", "Long",
+	"integer unsigned", "Long",
+	"bigint", "Long",
+	"bigint unsigned", "BigInteger",
+	"serial", "BigInteger",
+	"decimal", "BigDecimal",
+	"dec", "BigDecimal",
+	"numeric", "BigDecimal",
+	"fixed", "BigDecimal",
+	"float", "Float",
+	"double", "Double",
+	"double precision", "Double",
+	"real", "Double",
+	"date", "LocalDate",
+	"time", "LocalTime",
+	"datetime", "LocalDateTime",
+	"timestamp", "LocalDateTime",
+	"year", "Year",
+	"char", "String",
+	"varchar", "String",
+	"tinytext", "String",
+	"text", "String",
+	"mediumtext", "String",
+	"longtext", "String",
+	"json", "String",
+	"enum", "String",
+	"set", "String",
+	"binary", "byte[]",
+	"varbinary", "byte[]",
+	"tinyblob", "byte[]",
+	"blob", "byte[]",
+	"mediumblob", "byte[]",
+	"longblob", "byte[]",
+	"geometry", "byte[]",
+	"point", "byte[]",
+	"linestring", "byte[]",
+	"polygon", "byte[]",
+	"multipoint", "byte[]",
+	"multilinestring", "byte[]",
+	"multipolygon", "byte[]",
+	"geometrycollection", "byte[]",
+	"geomcollection", "byte[]",
+)
+
+// postgresTypeRules are the default rules of PostgreSQL by udt name, arrays and user defined types fall back to String
+var postgresTypeRules = newTypeRules(
+	"int2", "Integer",
+	"int4", "Integer",
+	"serial2", "Integer",
+	"serial4", "Integer",
+	"int8", "Long",
+	"serial8", "Long",
+	"numeric", "BigDecimal",
+	"money", "BigDecimal",
+	"float4", "Float",
+	"float8", "Double",
+	"bool", "Boolean",
+	"bytea", "byte[]",
+	"uuid", "UUID",
+	"date", "LocalDate",
+	"time", "LocalTime",
+	"timetz", "OffsetTime",
+	"timestamp", "LocalDateTime",
+	"timestamptz", "OffsetDateTime",
+	"varchar", "String",
+	"bpchar", "String",
+	"text", "String",
+	"json", "String",
+	"jsonb", "String",
+	"xml", "String",
+	"interval", "String",
+)
+
+// sqliteTypeRules take precedence over mysqlTypeRules for SQLite, whose INTEGER is 64 bits
+var sqliteTypeRules = newTypeRules(
+	"integer", "Long",
+	"real", "Double",
+	"numeric", "BigDecimal",
+)
+
+// javaTypePackages are the packages of the well known Java types the rules may name without a package
+var javaTypePackages = map[string]string{
+	"BigDecimal":     "java.math.BigDecimal",
+	"BigInteger":     "java.math.BigInteger",
+	"Date":           "java.util.Date",
+	"Duration":       "java.time.Duration",
+	"Instant":        "java.time.Instant",
+	"LocalDate":      "java.time.LocalDate",
+	"LocalDateTime":  "java.time.LocalDateTime",
+	"LocalTime":      "java.time.LocalTime",
+	"OffsetDateTime": "java.time.OffsetDateTime",
+	"OffsetTime":     "java.time.OffsetTime",
+	"UUID":           "java.util.UUID",
+	"Year":           "java.time.Year",
+	"ZonedDateTime":  "java.time.ZonedDateTime",
+}
+
+// resolveJavaType returns the simple name of a Java type and the import it needs,
+// e.g. java.time.Instant -> Instant, java.time.Instant.
+func resolveJavaType(javaType string) (string, string) {
+	if strings.ContainsAny(javaType, "<[") {
+		return javaType, ""
+	}
+	if i := strings.LastIndexByte(javaType, '.'); i >= 0 {
+		if strings.HasPrefix(javaType, "java.lang.") && i == len("java.lang") {
+			return javaType[i+1:], ""
+		}
+		return javaType[i+1:], javaType
+	}
+	return javaType, javaTypePackages[javaType]
+}
+
+// TypeMapping defines the user overrides of the SQL to Java type mapping, from the lowest precedence to the highest
+type TypeMapping struct {
+	Types   map[string]string `json:"types,omitempty" yaml:"types,omitempty"`     // by SQL type, e.g. tinyint(1) or bigint unsigned
+	Columns map[string]string `json:"columns,omitempty" yaml:"columns,omitempty"` // by column name, exact, glob or /regex/
+	Fields  map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`   // by table.column
+}
+
+// typeMapper maps columns to Java types
+type typeMapper struct {
+	defaults []typeRule
+	fallback func(t sqlType) string
+	types    []typeRule
+	columns  []columnTypeRule
+	fields   map[string]string
+}
+
+// columnTypeRule maps the columns matching a name pattern to a Java type
+type columnTypeRule struct {
+	pattern  tablePattern
+	javaType string
+}
+
+// typeMapping holds the user overrides loaded from -type-map
+var typeMapping = &TypeMapping{}
+
+// readTypeMapping loads type overrides from a JSON or YAML file.
+func readTypeMapping(filename string) (*TypeMapping, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	m := &TypeMapping{}
+	if isYAMLFile(filename) {
+		err = yaml.Unmarshal(content, m)
+	} else {
+		err = json.Unmarshal(content, m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if _, err = newTypeMapper(dialect, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return m, nil
+}
+
+// newTypeMapper returns the mapper of a dialect with the user overrides applied.
+func newTypeMapper(dialect string, overrides *TypeMapping) (*typeMapper, error) {
+	m := &typeMapper{defaults: mysqlTypeRules, fallback: func(sqlType) string { return "String" }, fields: make(map[string]string)}
+	switch dialect {
+	case dialectPostgres:
+		m.defaults = postgresTypeRules
+	case dialectSqlite:
+		m.defaults = append(append([]typeRule{}, sqliteTypeRules...), mysqlTypeRules...)
+		m.fallback = sqliteAffinityType
+	}
+	if overrides == nil {
+		return m, nil
+	}
+
+	for _, key := range sortedKeys(overrides.Types) {
+		t := parseSQLType(key)
+		if t.name == "" || overrides.Types[key] == "" {
+			return nil, fmt.Errorf("types: invalid mapping %s: %s", key, overrides.Types[key])
+		}
+		m.types = append(m.types, typeRule{pattern: t, javaType: overrides.Types[key]})
+	}
+	// exact names go first, then patterns in key order
+	keys := sortedKeys(overrides.Columns)
+	for _, exact := range []bool{true, false} {
+		for _, key := range keys {
+			pattern, err := parseTablePattern(strings.TrimSpace(key))
+			if err != nil || pattern.raw == "" || overrides.Columns[key] == "" {
+				return nil, fmt.Errorf("columns: invalid mapping %s: %s", key, overrides.Columns[key])
+			}
+			if pattern.exact == exact {
+				m.columns = append(m.columns, columnTypeRule{pattern: pattern, javaType: overrides.Columns[key]})
+			}
+		}
+	}
+	for key, javaType := range overrides.Fields {
+		table, column, ok := strings.Cut(key, ".")
+		if !ok || table == "" || column == "" || javaType == "" {
+			return nil, fmt.Errorf("fields: invalid mapping %s: %s, expected table.column", key, javaType)
+		}
+		m.fields[strings.ToLower(key)] = javaType
+	}
+	return m, nil
+}
+
+// javaType returns the Java type of a column and the import it needs.
+func (m *typeMapper) javaType(tableName string, col ColumnsStatement) (string, string) {
+	if javaType, ok := m.fields[strings.ToLower(tableName+"."+col.Field)]; ok {
+		return resolveJavaType(javaType)
+	}
+	for _, rule := range m.columns {
+		if rule.pattern.match(col.Field) {
+			return resolveJavaType(rule.javaType)
+		}
+	}
+	t := columnSQLType(col)
+	if javaType := matchTypeRules(m.types, t); javaType != "" {
+		return resolveJavaType(javaType)
+	}
+	if javaType := matchTypeRules(m.defaults, t); javaType != "" {
+		return resolveJavaType(javaType)
+	}
+	return resolveJavaType(m.fallback(t))
+}
+
+// matchTypeRules returns the Java type of the most specific rule matching a type, the first one wins a tie.
+func matchTypeRules(rules []typeRule, t sqlType) string {
+	best, javaType := -1, ""
+	for _, rule := range rules {
+		if specificity := rule.matches(t); specificity > best {
+			best, javaType = specificity, rule.javaType
+		}
+	}
+	return javaType
+}
+
+// sqliteAffinityType returns the Java type of a SQLite type name by the type affinity rules.
+func sqliteAffinityType(t sqlType) string {
+	switch {
+	case strings.Contains(t.name, "int"):
+		return "Long"
+	case strings.Contains(t.name, "char"), strings.Contains(t.name, "clob"), strings.Contains(t.name, "text"):
+		return "String"
+	case strings.Contains(t.name, "blob"), t.name == "":
+		return "byte[]"
+	case strings.Contains(t.name, "real"), strings.Contains(t.name, "floa"), strings.Contains(t.name, "doub"):
+		return "Double"
+	}
+	return "BigDecimal"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSQLType(t *testing.T) {
+	tests := []struct {
+		in       string
+		name     string
+		args     []string
+		unsigned bool
+	}{
+		{"bigint", "bigint", nil, false},
+		{"bigint(20) unsigned", "bigint", []string{"20"}, true},
+		{"INT(10) UNSIGNED ZEROFILL", "int", []string{"10"}, true},
+		{"decimal(10, 2)", "decimal", []string{"10", "2"}, false},
+		{"timestamp(3) with time zone", "timestamp with time zone", []string{"3"}, false},
+		{"double precision", "double precision", nil, false},
+		{"enum('a,b','c')", "enum", []string{"'a,b'", "'c'"}, false},
+		{"varchar()", "varchar", nil, false},
+	}
+	for _, tt := range tests {
+		got := parseSQLType(tt.in)
+		if got.name != tt.name || !slices.Equal(got.args, tt.args) || got.unsigned != tt.unsigned {
+			t.Errorf("parseSQLType(%q) = %+v, want {name:%s args:%v unsigned:%v}", tt.in, got, tt.name, tt.args, tt.unsigned)
+		}
+	}
+}
+
+func TestTypeMapperJavaType(t *testing.T) {
+	overrides := &TypeMapping{
+		Types:   map[string]string{"json": "com.fasterxml.jackson.databind.JsonNode", "datetime(3)": "Instant"},
+		Columns: map[string]string{`/^c_\d{1,3}$/`: "Short", "*_flag": "Boolean", "is_deleted": "Integer"},
+		Fields:  map[string]string{"tb_user.ext": "com.example.UserExt"},
+	}
+	m, err := newTypeMapper(dialectMySQL, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		table, column, columnType, extra string
+		javaType, pkg                    string
+	}{
+		{"tb_user", "id", "bigint", "auto_increment", "Long", ""},
+		{"tb_user", "id", "bigint unsigned", "auto_increment", "BigInteger", "java.math.BigInteger"},
+		{"tb_user", "n", "int unsigned", "", "Long", ""},
+		{"tb_user", "ok", "tinyint(1)", "", "Boolean", ""},
+		{"tb_user", "level", "tinyint(4)", "", "Integer", ""},
+		{"tb_user", "price", "decimal(10,2)", "", "BigDecimal", "java.math.BigDecimal"},
+		{"tb_user", "created", "datetime", "", "LocalDateTime", "java.time.LocalDateTime"},
+		{"tb_user", "updated", "datetime(3)", "", "Instant", "java.time.Instant"},
+		{"tb_user", "data", "json", "", "JsonNode", "com.fasterxml.jackson.databind.JsonNode"},
+		{"tb_user", "c_12", "int", "", "Short", ""},
+		{"tb_user", "c_1234", "int", "", "Integer", ""},
+		{"tb_user", "vip_flag", "int", "", "Boolean", ""},
+		{"tb_user", "is_deleted", "tinyint(1)", "", "Integer", ""},
+		{"tb_user", "ext", "json", "", "UserExt", "com.example.UserExt"},
+		{"tb_order", "ext", "json", "", "JsonNode", "com.fasterxml.jackson.databind.JsonNode"},
+	}
+	for _, tt := range tests {
+		javaType, pkg := m.javaType(tt.table, ColumnsStatement{Field: tt.column, Type: tt.columnType, Extra: tt.extra})
+		if javaType != tt.javaType || pkg != tt.pkg {
+			t.Errorf("%s.%s %s = %s %s, want %s %s", tt.table, tt.column, tt.columnType, javaType, pkg, tt.javaType, tt.pkg)
+		}
+	}
+}
+
+func TestTypeMapperDialects(t *testing.T) {
+	tests := []struct {
+		dialect, columnType, dataType string
+		javaType, pkg                 string
+	}{
+		{dialectPostgres, "smallint", "int2", "Integer", ""},
+		{dialectPostgres, "integer", "int4", "Integer", ""},
+		{dialectPostgres, "bigint", "int8", "Long", ""},
+		{dialectPostgres, "numeric(10,2)", "numeric", "BigDecimal", "java.math.BigDecimal"},
+		{dialectPostgres, "double precision", "float8", "Double", ""},
+		{dialectPostgres, "boolean", "bool", "Boolean", ""},
+		{dialectPostgres, "character varying(32)", "varchar", "String", ""},
+		{dialectPostgres, "uuid", "uuid", "UUID", "java.util.UUID"},
+		{dialectPostgres, "timestamp(3) without time zone", "timestamp", "LocalDateTime", "java.time.LocalDateTime"},
+		{dialectPostgres, "timestamp with time zone", "timestamptz", "OffsetDateTime", "java.time.OffsetDateTime"},
+		{dialectPostgres, "jsonb", "jsonb", "String", ""},
+		{dialectPostgres, "bytea", "bytea", "byte[]", ""},
+		{dialectPostgres, "text[]", "_text", "String", ""},
+		{dialectPostgres, "mood", "mood", "String", ""},
+		{dialectSqlite, "integer", "integer", "Long", ""},
+		{dialectSqlite, "int", "int", "Integer", ""},
+		{dialectSqlite, "tinyint(1)", "tinyint", "Boolean", ""},
+		{dialectSqlite, "real", "real", "Double", ""},
+		{dialectSqlite, "numeric", "numeric", "BigDecimal", "java.math.BigDecimal"},
+		{dialectSqlite, "datetime", "datetime", "LocalDateTime", "java.time.LocalDateTime"},
+		{dialectSqlite, "int8", "int8", "Long", ""},
+		{dialectSqlite, "nvarchar(20)", "nvarchar", "String", ""},
+		{dialectSqlite, "clob", "clob", "String", ""},
+		{dialectSqlite, "floating point", "floating point", "Long", ""}, // INTEGER affinity by the int in point
+		{dialectSqlite, "float4", "float4", "Double", ""},
+		{dialectSqlite, "", "", "byte[]", ""},
+		{dialectSqlite, "money", "money", "BigDecimal", "java.math.BigDecimal"},
+	}
+	for _, tt := range tests {
+		m, err := newTypeMapper(tt.dialect, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		javaType, pkg := m.javaType("tb_user", ColumnsStatement{Field: "c", Type: tt.columnType, DataType: tt.dataType})
+		if javaType != tt.javaType || pkg != tt.pkg {
+			t.Errorf("%s %s (%s) = %s %s, want %s %s", tt.dialect, tt.columnType, tt.dataType, javaType, pkg, tt.javaType, tt.pkg)
+		}
+	}
+}
+
+func TestNewTypeMapperInvalid(t *testing.T) {
+	tests := []*TypeMapping{
+		{Types: map[string]string{"json": ""}},
+		{Columns: map[string]string{"/(/": "String"}},
+		{Columns: map[string]string{" ": "String"}},
+		{Fields: map[string]string{"ext": "String"}},
+	}
+	for _, overrides := range tests {
+		if _, err := newTypeMapper(dialectMySQL, overrides); err == nil {
+			t.Errorf("newTypeMapper(%+v) succeeded, want an error", overrides)
+		}
+	}
+}
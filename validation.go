@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	validationJavax   = "javax"
+	validationJakarta = "jakarta"
+)
+
+// validation is the namespace of the Bean Validation constraints of entity members, see -validation,
+// empty generates none
+var validation = ""
+
+// validateValidation checks a Bean Validation namespace.
+func validateValidation(name string) error {
+	if name != "" && name != validationJavax && name != validationJakarta {
+		return fmt.Errorf("unknown validation %s, expected %s or %s", name, validationJavax, validationJakarta)
+	}
+	return nil
+}
+
+// eeNamespace returns the namespace of the JPA and @Resource annotations, which follows -validation jakarta so that
+// a Spring Boot 3 project does not mix jakarta with javax.
+func eeNamespace() string {
+	if validation == validationJakarta {
+		return validationJakarta
+	}
+	return validationJavax
+}
+
+// parseValidationConstraints puts the Bean Validation constraints of their columns on javaFields:
+// 1. @NotNull for a NOT NULL column the database does not fill in, @Nullable for a nullable one;
+// 2. @Size(max = n) for a char or varchar column;
+// 3. @Digits(integer, fraction) for a decimal column;
+// 4. @Min(0) for an unsigned number.
+func parseValidationConstraints(table *Table, javaFields []JavaField) {
+	if validation == "" {
+		return
+	}
+	constraint := func(f *JavaField, annotation, class string) {
+		f.Constraints = append(f.Constraints, annotation)
+		f.ConstraintImports = append(f.ConstraintImports, class)
+	}
+	for i, col := range table.Columns {
+		f := &javaFields[i]
+		dataType := strings.ToLower(col.DataType)
+		switch {
+		case strings.EqualFold(col.Null, "YES"):
+			constraint(f, "@Nullable", validation+".annotation.Nullable")
+		case !generatedColumn(col) && !col.HasDefault:
+			constraint(f, "@NotNull", validation+".validation.constraints.NotNull")
+		}
+		switch {
+		case f.JavaType == "String" && (dataType == "char" || dataType == "varchar") && col.CharMaxLength > 0:
+			constraint(f, fmt.Sprintf("@Size(max = %d)", col.CharMaxLength), validation+".validation.constraints.Size")
+		case f.JavaType == "BigDecimal" && col.NumericPrecision > 0:
+			constraint(f, fmt.Sprintf("@Digits(integer = %d, fraction = %d)", col.NumericPrecision-col.NumericScale, col.NumericScale),
+				validation+".validation.constraints.Digits")
+		}
+		unsigned := col.Unsigned || strings.Contains(strings.ToLower(col.Type), "unsigned")
+		if unsigned && slices.Contains([]string{"Byte", "Short", "Integer", "Long", "BigInteger", "BigDecimal"}, f.JavaType) {
+			constraint(f, "@Min(0)", validation+".validation.constraints.Min")
+		}
+	}
+}
+
+// generatedColumn reports whether the database fills in a column, an auto increment or a generated one.
+func generatedColumn(col ColumnsStatement) bool {
+	return strings.Contains(strings.ToLower(col.Extra), "auto_increment") || col.GenerationExpression != ""
+}
+
+// validatedJavaFields returns copies of javaFields annotated with their constraints but those named in skipped,
+// Kotlin ones target the backing field and leave nullability to the type.
+func validatedJavaFields(javaFields []JavaField, skipped ...string) []JavaField {
+	fields := slices.Clone(javaFields)
+	for i := range fields {
+		f := &fields[i]
+		if slices.Contains(skipped, f.Field) {
+			continue
+		}
+		annotations, imports := slices.Clone(f.Annotations), slices.Clone(f.Imports)
+		for j, constraint := range f.Constraints {
+			if lang == langKotlin {
+				if constraint == "@Nullable" {
+					continue
+				}
+				constraint = "@field:" + strings.TrimPrefix(constraint, "@")
+			}
+			annotations = append(annotations, constraint)
+			imports = append(imports, f.ConstraintImports[j])
+		}
+		f.Annotations, f.Imports = annotations, imports
+	}
+	return fields
+}
+
+// fieldImportCodes returns the sorted import lines of the types and constraints of javaFields,
+// for the command and DTO classes of custom templates.
+func fieldImportCodes(javaFields []JavaField) string {
+	imports := make([]string, 0)
+	for _, f := range javaFields {
+		imports = append(append(append(imports, f.PackageName), f.Imports...), f.ConstraintImports...)
+	}
+	return sortJavaImports(slices.DeleteFunc(imports, func(pkg string) bool { return pkg == "" }))
+}
+
+// parseCommandCodes returns the imports and the validated members of the command creating the entity of a table,
+// the columns the database or the base PO fill in are left out. Kotlin properties are all nullable so that the
+// constraints rather than the deserializer reject a missing value. Nothing is generated without -validation.
+func parseCommandCodes(table *Table, javaFields []JavaField) commandCodes {
+	if validation == "" {
+		return commandCodes{}
+	}
+	skipped := basePoFields()
+	for i, col := range table.Columns {
+		if generatedColumn(col) {
+			skipped = append(skipped, javaFields[i].Field)
+		}
+	}
+	fields := slices.DeleteFunc(validatedJavaFields(javaFields), func(f JavaField) bool { return slices.Contains(skipped, f.Field) })
+	if lang == langKotlin {
+		for i := range fields {
+			fields[i].Nullable = true
+		}
+		imports, properties := parseKotlinImportsAndProperties(fields)
+		return commandCodes{Imports: sortKotlinImports(imports), Fields: properties}
+	}
+	_, fieldCodes := parseJavaImportsAndFields(fields)
+	return commandCodes{Imports: fieldImportCodes(fields), Fields: fieldCodes}
+}
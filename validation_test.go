@@ -0,0 +1,77 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseValidationConstraints(t *testing.T) {
+	tables, err := parseDDL(`CREATE TABLE tb_order (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  code varchar(32) NOT NULL,
+  remark varchar(64) NOT NULL DEFAULT '',
+  status tinyint NOT NULL DEFAULT 0,
+  amount decimal(10,2) NOT NULL,
+  user_id bigint DEFAULT NULL,
+  PRIMARY KEY (id)
+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(v, d string) { validation, dialect = v, d }(validation, dialect)
+	validation, dialect = validationJakarta, dialectMySQL
+	if javaTypeMapper, err = newTypeMapper(dialect, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := parseJavaFields(tables[0])
+	parseValidationConstraints(tables[0], fields)
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{"id", []string{"@Min(0)"}},
+		{"code", []string{"@NotNull", "@Size(max = 32)"}},
+		{"remark", []string{"@Size(max = 64)"}},
+		{"status", nil},
+		{"amount", []string{"@NotNull", "@Digits(integer = 8, fraction = 2)"}},
+		{"userId", []string{"@Nullable"}},
+	}
+	for i, tt := range tests {
+		if fields[i].Field != tt.field {
+			t.Fatalf("field %d = %s, want %s", i, fields[i].Field, tt.field)
+		}
+		if !slices.Equal(fields[i].Constraints, tt.want) {
+			t.Errorf("%s constraints = %v, want %v", tt.field, fields[i].Constraints, tt.want)
+		}
+	}
+}
+
+func TestParseCommandCodes(t *testing.T) {
+	tables, err := parseDDL(`CREATE TABLE tb_order (
+  id bigint NOT NULL AUTO_INCREMENT,
+  code varchar(32) NOT NULL,
+  total int AS (1) STORED,
+  ctime datetime,
+  PRIMARY KEY (id)
+)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(v, d string) { validation, dialect = v, d }(validation, dialect)
+	validation, dialect = validationJavax, dialectMySQL
+	if javaTypeMapper, err = newTypeMapper(dialect, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := parseJavaFields(tables[0])
+	parseValidationConstraints(tables[0], fields)
+	codes := parseCommandCodes(tables[0], fields)
+	want := "  @NotNull\n  @Size(max = 32)\n  private String code; // "
+	if codes.Fields != want {
+		t.Errorf("fields = %q, want %q", codes.Fields, want)
+	}
+	if want = "import javax.validation.constraints.NotNull;\nimport javax.validation.constraints.Size;"; codes.Imports != want {
+		t.Errorf("imports = %q, want %q", codes.Imports, want)
+	}
+}